@@ -0,0 +1,514 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+
+	"github.com/pkg/errors"
+)
+
+/**
+ * Archive
+ *
+ * A single file containing many inspector transactions, each serialized with Transaction.Write,
+ * and a second file indexing them by tx hash.
+ *
+ * Data file: header, then records of
+ *   tx hash (32 bytes), payload size (uint32), payload, crc32c of the preceding record bytes
+ *
+ * Index file: header, then entries of
+ *   tx hash (32 bytes), record offset in the data file (uint64)
+ *
+ * Records and index entries are only ever appended, and an index entry is written after its
+ * record. So after a crash the index can only be missing entries at the end, and the data file can
+ * only have a torn record at the end. Both are repaired when the archive is opened.
+ */
+
+const (
+	dataVersion  = uint8(1)
+	indexVersion = uint8(1)
+
+	headerSize     = 5
+	recordOverhead = bitcoin.Hash32Size + 4 + 4
+	entrySize      = bitcoin.Hash32Size + 8
+
+	// IndexExtension is appended to the data file path to get the index file path.
+	IndexExtension = ".idx"
+)
+
+var (
+	dataMagic  = []byte("ITXA")
+	indexMagic = []byte("ITXI")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// ErrNotFound means the archive doesn't contain the tx.
+	ErrNotFound = errors.New("Not found")
+
+	// ErrAlreadyExists means the archive already contains the tx.
+	ErrAlreadyExists = errors.New("Already exists")
+
+	// ErrInvalidHeader means a file is not an archive file or is an unsupported version.
+	ErrInvalidHeader = errors.New("Invalid header")
+
+	// ErrInvalidRecord means a record is incomplete or its checksum doesn't match.
+	ErrInvalidRecord = errors.New("Invalid record")
+
+	// ErrWrongHash means a serialized tx passed to AppendRaw isn't the tx with the hash.
+	ErrWrongHash = errors.New("Wrong hash")
+)
+
+// Archive is an append only container of inspector transactions with random access by tx hash.
+// It is safe for concurrent use.
+type Archive struct {
+	data  *os.File
+	index *os.File
+	opts  inspector.ParseOptions
+
+	// offsets of the records in the data file by tx hash.
+	offsets map[bitcoin.Hash32]int64
+
+	// size is the size of the valid data in the data file.
+	size int64
+
+	// entries is the number of entries in the index file.
+	entries int64
+
+	lock sync.RWMutex
+}
+
+// Open opens the archive at the path, creating it if it doesn't exist, and repairs any damage from
+// an interrupted append. The index file is at the path plus IndexExtension. The options are used
+// to parse transactions read from the archive.
+func Open(path string, opts inspector.ParseOptions) (*Archive, error) {
+	data, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "open data")
+	}
+
+	index, err := os.OpenFile(path+IndexExtension, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		data.Close()
+		return nil, errors.Wrap(err, "open index")
+	}
+
+	result := &Archive{
+		data:    data,
+		index:   index,
+		opts:    opts,
+		offsets: make(map[bitcoin.Hash32]int64),
+	}
+
+	if err := result.recover(); err != nil {
+		data.Close()
+		index.Close()
+		return nil, errors.Wrap(err, "recover")
+	}
+
+	return result, nil
+}
+
+// Close syncs and closes the archive files.
+func (a *Archive) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	syncErr := a.sync()
+
+	if err := a.data.Close(); err != nil {
+		return errors.Wrap(err, "close data")
+	}
+
+	if err := a.index.Close(); err != nil {
+		return errors.Wrap(err, "close index")
+	}
+
+	return syncErr
+}
+
+// Sync commits the archive files to stable storage.
+func (a *Archive) Sync() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.sync()
+}
+
+func (a *Archive) sync() error {
+	if err := a.data.Sync(); err != nil {
+		return errors.Wrap(err, "data")
+	}
+
+	if err := a.index.Sync(); err != nil {
+		return errors.Wrap(err, "index")
+	}
+
+	return nil
+}
+
+// Count returns the number of transactions in the archive.
+func (a *Archive) Count() int {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return len(a.offsets)
+}
+
+// Contains returns true if the archive contains the tx.
+func (a *Archive) Contains(hash bitcoin.Hash32) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	_, exists := a.offsets[hash]
+	return exists
+}
+
+// Append adds the transaction to the end of the archive. It returns ErrAlreadyExists if the
+// archive already contains the tx.
+func (a *Archive) Append(itx *inspector.Transaction) error {
+	payload := &bytes.Buffer{}
+	if err := itx.Write(payload); err != nil {
+		return errors.Wrap(err, "write tx")
+	}
+
+	return a.append(itx.Hash, payload.Bytes())
+}
+
+// AppendRaw adds a transaction already serialized with Transaction.Write to the end of the
+// archive. The payload is read to verify it contains the tx with the hash, so a bad payload can't
+// be stored under another tx's hash. It returns ErrAlreadyExists if the archive already contains
+// the tx.
+func (a *Archive) AppendRaw(hash bitcoin.Hash32, payload []byte) error {
+	itx, err := a.parse(payload)
+	if err != nil {
+		return err
+	}
+
+	if !itx.Hash.Equal(&hash) {
+		return errors.Wrapf(ErrWrongHash, "got %s, want %s", itx.Hash, hash)
+	}
+
+	return a.append(hash, payload)
+}
+
+func (a *Archive) append(hash bitcoin.Hash32, payload []byte) error {
+	if uint64(len(payload)) > uint64(^uint32(0)) {
+		return fmt.Errorf("Payload too large : %d", len(payload))
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, exists := a.offsets[hash]; exists {
+		return errors.Wrap(ErrAlreadyExists, hash.String())
+	}
+
+	record := encodeRecord(hash, payload)
+	if _, err := a.data.WriteAt(record, a.size); err != nil {
+		a.data.Truncate(a.size)
+		return errors.Wrap(err, "write record")
+	}
+
+	if err := a.writeEntry(hash, a.size); err != nil {
+		a.data.Truncate(a.size)
+		return errors.Wrap(err, "write index")
+	}
+
+	a.offsets[hash] = a.size
+	a.size += int64(len(record))
+	return nil
+}
+
+// Get returns the transaction with the specified hash or ErrNotFound.
+func (a *Archive) Get(hash bitcoin.Hash32) (*inspector.Transaction, error) {
+	payload, err := a.GetRaw(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.parse(payload)
+}
+
+// GetRaw returns the serialized transaction with the specified hash or ErrNotFound.
+func (a *Archive) GetRaw(hash bitcoin.Hash32) ([]byte, error) {
+	a.lock.RLock()
+	offset, exists := a.offsets[hash]
+	size := a.size
+	a.lock.RUnlock()
+
+	if !exists {
+		return nil, errors.Wrap(ErrNotFound, hash.String())
+	}
+
+	recordHash, payload, _, err := readRecord(a.data, offset, size)
+	if err != nil {
+		return nil, errors.Wrapf(err, "record at %d", offset)
+	}
+
+	if !recordHash.Equal(&hash) {
+		return nil, errors.Wrapf(ErrInvalidRecord, "record at %d is %s", offset, recordHash)
+	}
+
+	return payload, nil
+}
+
+// Iterate calls the function for each transaction in the order they were appended. It stops and
+// returns the error if the function returns an error. Transactions appended during the iteration
+// are not included.
+func (a *Archive) Iterate(f func(itx *inspector.Transaction) error) error {
+	return a.IterateRaw(func(hash bitcoin.Hash32, payload []byte) error {
+		itx, err := a.parse(payload)
+		if err != nil {
+			return errors.Wrap(err, hash.String())
+		}
+
+		return f(itx)
+	})
+}
+
+// IterateRaw calls the function for each serialized transaction in the order they were appended.
+// It stops and returns the error if the function returns an error.
+func (a *Archive) IterateRaw(f func(hash bitcoin.Hash32, payload []byte) error) error {
+	a.lock.RLock()
+	size := a.size
+	a.lock.RUnlock()
+
+	// Records before size are never modified so they can be read without the lock.
+	r := bufio.NewReader(io.NewSectionReader(a.data, headerSize, size-headerSize))
+	offset := int64(headerSize)
+	for offset < size {
+		hash, payload, err := decodeRecord(r, size-offset)
+		if err != nil {
+			return errors.Wrapf(err, "record at %d", offset)
+		}
+
+		if err := f(hash, payload); err != nil {
+			return err
+		}
+
+		offset += int64(recordOverhead + len(payload))
+	}
+
+	return nil
+}
+
+func (a *Archive) parse(payload []byte) (*inspector.Transaction, error) {
+	itx := &inspector.Transaction{}
+	if err := itx.ReadWithOptions(bytes.NewReader(payload), a.opts); err != nil {
+		return nil, errors.Wrap(err, "read tx")
+	}
+
+	return itx, nil
+}
+
+// recover loads the index, drops index entries for records that are not complete, indexes
+// complete records that are missing from the index, and truncates a torn record at the end of the
+// data file.
+func (a *Archive) recover() error {
+	dataSize, err := prepareHeader(a.data, dataMagic, dataVersion)
+	if err != nil {
+		return errors.Wrap(err, "data header")
+	}
+
+	indexSize, err := prepareHeader(a.index, indexMagic, indexVersion)
+	if err != nil {
+		return errors.Wrap(err, "index header")
+	}
+
+	hashes, offsets, err := readEntries(a.index, (indexSize-headerSize)/entrySize)
+	if err != nil {
+		return errors.Wrap(err, "read index")
+	}
+
+	// Entries are in the same order as the records, so any that are invalid are at the end.
+	scanOffset := int64(headerSize)
+	for len(offsets) > 0 {
+		last := len(offsets) - 1
+		hash, payload, _, err := readRecord(a.data, offsets[last], dataSize)
+		if err == nil && hash.Equal(&hashes[last]) {
+			scanOffset = offsets[last] + int64(recordOverhead+len(payload))
+			break
+		}
+
+		hashes = hashes[:last]
+		offsets = offsets[:last]
+	}
+
+	a.entries = int64(len(offsets))
+	if err := a.index.Truncate(headerSize + a.entries*entrySize); err != nil {
+		return errors.Wrap(err, "truncate index")
+	}
+
+	for i, hash := range hashes {
+		a.offsets[hash] = offsets[i]
+	}
+
+	// Index complete records after the last indexed record.
+	for scanOffset < dataSize {
+		hash, _, end, err := readRecord(a.data, scanOffset, dataSize)
+		if err != nil {
+			break // torn record
+		}
+
+		if _, exists := a.offsets[hash]; !exists {
+			if err := a.writeEntry(hash, scanOffset); err != nil {
+				return errors.Wrap(err, "write index")
+			}
+			a.offsets[hash] = scanOffset
+		}
+
+		scanOffset = end
+	}
+
+	if scanOffset < dataSize {
+		if err := a.data.Truncate(scanOffset); err != nil {
+			return errors.Wrap(err, "truncate data")
+		}
+	}
+
+	a.size = scanOffset
+	return nil
+}
+
+func (a *Archive) writeEntry(hash bitcoin.Hash32, offset int64) error {
+	var entry [entrySize]byte
+	copy(entry[:], hash[:])
+	binary.LittleEndian.PutUint64(entry[bitcoin.Hash32Size:], uint64(offset))
+
+	entryOffset := headerSize + a.entries*entrySize
+	if _, err := a.index.WriteAt(entry[:], entryOffset); err != nil {
+		a.index.Truncate(entryOffset)
+		return err
+	}
+
+	a.entries++
+	return nil
+}
+
+// prepareHeader writes the header to an empty file, or verifies the header of an existing file. It
+// returns the size of the file.
+func prepareHeader(file *os.File, magic []byte, version uint8) (int64, error) {
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, errors.Wrap(err, "stat")
+	}
+
+	header := append(append([]byte{}, magic...), version)
+
+	if stat.Size() == 0 {
+		if _, err := file.WriteAt(header, 0); err != nil {
+			return 0, errors.Wrap(err, "write")
+		}
+		return headerSize, nil
+	}
+
+	if stat.Size() < headerSize {
+		return 0, errors.Wrapf(ErrInvalidHeader, "size %d", stat.Size())
+	}
+
+	fileHeader := make([]byte, headerSize)
+	if _, err := file.ReadAt(fileHeader, 0); err != nil {
+		return 0, errors.Wrap(err, "read")
+	}
+
+	if !bytes.Equal(fileHeader[:len(magic)], magic) {
+		return 0, errors.Wrap(ErrInvalidHeader, "magic")
+	}
+
+	if fileHeader[len(magic)] != version {
+		return 0, errors.Wrapf(ErrInvalidHeader, "version %d", fileHeader[len(magic)])
+	}
+
+	return stat.Size(), nil
+}
+
+// readEntries reads the complete entries from the index file.
+func readEntries(file *os.File, count int64) ([]bitcoin.Hash32, []int64, error) {
+	r := bufio.NewReader(io.NewSectionReader(file, headerSize, count*entrySize))
+	hashes := make([]bitcoin.Hash32, 0, count)
+	offsets := make([]int64, 0, count)
+
+	var entry [entrySize]byte
+	for i := int64(0); i < count; i++ {
+		if _, err := io.ReadFull(r, entry[:]); err != nil {
+			return nil, nil, errors.Wrapf(err, "entry %d", i)
+		}
+
+		var hash bitcoin.Hash32
+		copy(hash[:], entry[:bitcoin.Hash32Size])
+		hashes = append(hashes, hash)
+		offsets = append(offsets,
+			int64(binary.LittleEndian.Uint64(entry[bitcoin.Hash32Size:])))
+	}
+
+	return hashes, offsets, nil
+}
+
+func encodeRecord(hash bitcoin.Hash32, payload []byte) []byte {
+	record := make([]byte, recordOverhead+len(payload))
+	copy(record, hash[:])
+	binary.LittleEndian.PutUint32(record[bitcoin.Hash32Size:], uint32(len(payload)))
+	copy(record[bitcoin.Hash32Size+4:], payload)
+
+	checksumOffset := len(record) - 4
+	binary.LittleEndian.PutUint32(record[checksumOffset:],
+		crc32.Checksum(record[:checksumOffset], crcTable))
+	return record
+}
+
+// readRecord reads and verifies the record at the offset. It returns the offset after the record.
+func readRecord(file io.ReaderAt, offset, size int64) (bitcoin.Hash32, []byte, int64, error) {
+	if offset < headerSize || offset >= size {
+		return bitcoin.Hash32{}, nil, 0, errors.Wrapf(ErrInvalidRecord, "offset %d", offset)
+	}
+
+	r := io.NewSectionReader(file, offset, size-offset)
+	hash, payload, err := decodeRecord(r, size-offset)
+	if err != nil {
+		return bitcoin.Hash32{}, nil, 0, err
+	}
+
+	return hash, payload, offset + int64(recordOverhead+len(payload)), nil
+}
+
+// decodeRecord reads and verifies a record that must fit in the remaining bytes.
+func decodeRecord(r io.Reader, remaining int64) (bitcoin.Hash32, []byte, error) {
+	var hash bitcoin.Hash32
+	if remaining < recordOverhead {
+		return hash, nil, errors.Wrap(ErrInvalidRecord, "incomplete header")
+	}
+
+	var header [bitcoin.Hash32Size + 4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return hash, nil, errors.Wrap(err, "header")
+	}
+
+	payloadSize := int64(binary.LittleEndian.Uint32(header[bitcoin.Hash32Size:]))
+	if recordOverhead+payloadSize > remaining {
+		return hash, nil, errors.Wrapf(ErrInvalidRecord, "incomplete payload of %d bytes",
+			payloadSize)
+	}
+
+	body := make([]byte, payloadSize+4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return hash, nil, errors.Wrap(err, "payload")
+	}
+	payload := body[:payloadSize]
+
+	crc := crc32.Update(crc32.Checksum(header[:], crcTable), crcTable, payload)
+	if binary.LittleEndian.Uint32(body[payloadSize:]) != crc {
+		return hash, nil, errors.Wrap(ErrInvalidRecord, "checksum")
+	}
+
+	copy(hash[:], header[:bitcoin.Hash32Size])
+	return hash, payload, nil
+}
@@ -0,0 +1,245 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Archive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "itx.archive")
+	opts := inspector.DefaultParseOptions(true)
+
+	archive, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("Failed to open archive : %s", err)
+	}
+
+	var itxs []*inspector.Transaction
+	for i := 0; i < 10; i++ {
+		itx := newTestTx(t, i)
+		if err := archive.Append(itx); err != nil {
+			t.Fatalf("Failed to append tx %d : %s", i, err)
+		}
+		itxs = append(itxs, itx)
+	}
+
+	if err := archive.Append(itxs[3]); errors.Cause(err) != ErrAlreadyExists {
+		t.Fatalf("Wrong duplicate error : got %v, want %v", err, ErrAlreadyExists)
+	}
+
+	if _, err := archive.Get(bitcoin.Hash32{}); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("Wrong missing error : got %v, want %v", err, ErrNotFound)
+	}
+
+	checkArchive(t, archive, itxs)
+
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive : %s", err)
+	}
+
+	archive, err = Open(path, opts)
+	if err != nil {
+		t.Fatalf("Failed to reopen archive : %s", err)
+	}
+	defer archive.Close()
+
+	checkArchive(t, archive, itxs)
+}
+
+func Test_Archive_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "itx.archive")
+	opts := inspector.DefaultParseOptions(true)
+
+	archive, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("Failed to open archive : %s", err)
+	}
+
+	var itxs []*inspector.Transaction
+	for i := 0; i < 5; i++ {
+		itx := newTestTx(t, i)
+		if err := archive.Append(itx); err != nil {
+			t.Fatalf("Failed to append tx %d : %s", i, err)
+		}
+		itxs = append(itxs, itx)
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive : %s", err)
+	}
+
+	dataStat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat data : %s", err)
+	}
+
+	// Index lost its last two entries and the last one is torn.
+	if err := os.Truncate(path+IndexExtension, headerSize+3*entrySize-5); err != nil {
+		t.Fatalf("Failed to truncate index : %s", err)
+	}
+
+	// Torn record at the end of the data.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open data : %s", err)
+	}
+	torn := encodeRecord(newTestTx(t, 100).Hash, make([]byte, 100))
+	if _, err := file.Write(torn[:60]); err != nil {
+		t.Fatalf("Failed to write torn record : %s", err)
+	}
+	file.Close()
+
+	archive, err = Open(path, opts)
+	if err != nil {
+		t.Fatalf("Failed to recover archive : %s", err)
+	}
+
+	checkArchive(t, archive, itxs)
+
+	recoveredStat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat data : %s", err)
+	}
+	if recoveredStat.Size() != dataStat.Size() {
+		t.Fatalf("Wrong recovered data size : got %d, want %d", recoveredStat.Size(),
+			dataStat.Size())
+	}
+
+	indexStat, err := os.Stat(path + IndexExtension)
+	if err != nil {
+		t.Fatalf("Failed to stat index : %s", err)
+	}
+	if indexStat.Size() != headerSize+5*entrySize {
+		t.Fatalf("Wrong recovered index size : got %d, want %d", indexStat.Size(),
+			headerSize+5*entrySize)
+	}
+
+	// Appends continue after the recovered data.
+	itx := newTestTx(t, 5)
+	if err := archive.Append(itx); err != nil {
+		t.Fatalf("Failed to append tx : %s", err)
+	}
+	itxs = append(itxs, itx)
+
+	checkArchive(t, archive, itxs)
+	archive.Close()
+}
+
+func Test_Archive_AppendRaw(t *testing.T) {
+	archive, err := Open(filepath.Join(t.TempDir(), "itx.archive"),
+		inspector.DefaultParseOptions(true))
+	if err != nil {
+		t.Fatalf("Failed to open archive : %s", err)
+	}
+	defer archive.Close()
+
+	itx := newTestTx(t, 0)
+	other := newTestTx(t, 1)
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write(buf); err != nil {
+		t.Fatalf("Failed to write tx : %s", err)
+	}
+	payload := buf.Bytes()
+
+	if err := archive.AppendRaw(other.Hash, payload); errors.Cause(err) != ErrWrongHash {
+		t.Fatalf("Wrong error for wrong hash : got %v, want %v", err, ErrWrongHash)
+	}
+
+	corrupt := append([]byte{}, payload...)
+	corrupt[len(corrupt)-1]++
+	if err := archive.AppendRaw(itx.Hash, corrupt); err == nil {
+		t.Fatalf("Corrupt payload should fail")
+	}
+
+	if archive.Count() != 0 {
+		t.Fatalf("Invalid payloads should not be appended : %d txs", archive.Count())
+	}
+
+	if err := archive.AppendRaw(itx.Hash, payload); err != nil {
+		t.Fatalf("Failed to append raw tx : %s", err)
+	}
+
+	checkArchive(t, archive, []*inspector.Transaction{itx})
+}
+
+func checkArchive(t *testing.T, archive *Archive, itxs []*inspector.Transaction) {
+	if archive.Count() != len(itxs) {
+		t.Fatalf("Wrong count : got %d, want %d", archive.Count(), len(itxs))
+	}
+
+	for i, itx := range itxs {
+		read, err := archive.Get(itx.Hash)
+		if err != nil {
+			t.Fatalf("Failed to get tx %d : %s", i, err)
+		}
+
+		if !read.Hash.Equal(&itx.Hash) {
+			t.Fatalf("Wrong tx %d hash : got %s, want %s", i, read.Hash, itx.Hash)
+		}
+
+		if !read.IsTokenized() {
+			t.Fatalf("Tx %d action not decoded", i)
+		}
+	}
+
+	index := 0
+	if err := archive.Iterate(func(itx *inspector.Transaction) error {
+		if !itx.Hash.Equal(&itxs[index].Hash) {
+			return errors.Errorf("Wrong tx %d hash : got %s, want %s", index, itx.Hash,
+				itxs[index].Hash)
+		}
+		index++
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to iterate : %s", err)
+	}
+
+	if index != len(itxs) {
+		t.Fatalf("Wrong iterate count : got %d, want %d", index, len(itxs))
+	}
+}
+
+func newTestTx(t *testing.T, i int) *inspector.Transaction {
+	key, err := bitcoin.GenerateKey(bitcoin.TestNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+
+	lockingScript, err := key.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	script, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	tx := wire.NewMsgTx(1)
+	var prevHash bitcoin.Hash32
+	prevHash[0] = byte(i)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil))
+	tx.AddTxOut(wire.NewTxOut(1000, lockingScript))
+	tx.AddTxOut(wire.NewTxOut(0, script))
+
+	outputs := []*wire.TxOut{wire.NewTxOut(2000, lockingScript)}
+	itx, err := inspector.NewTransactionFromOutputs(context.Background(), *tx.TxHash(), tx,
+		outputs, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	return itx
+}
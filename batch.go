@@ -0,0 +1,126 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// PromoteBatch promotes the txs concurrently. Inputs spending outputs of other txs in the batch are
+// resolved from those txs and the remaining outputs are requested from the node in one
+// de-duplicated GetOutputs call. Txs are promoted by up to GOMAXPROCS workers.
+//
+// The returned errors correspond to the txs and are nil for txs that were promoted. When the node
+// doesn't have an output, only the txs spending it fail.
+func PromoteBatch(ctx context.Context, node NodeInterface, txs []*Transaction,
+	opts ParseOptions) []error {
+
+	errs := make([]error, len(txs))
+
+	batchTxs := make(map[bitcoin.Hash32]*wire.MsgTx, len(txs))
+	for _, itx := range txs {
+		batchTxs[itx.Hash] = itx.MsgTx
+	}
+
+	// Outpoints that aren't in the batch and the txs that spend them.
+	var outpoints []wire.OutPoint
+	spenders := make(map[wire.OutPoint][]int)
+	for i, itx := range txs {
+		for _, txin := range itx.MsgTx.TxIn {
+			if txin.PreviousOutPoint.Index == 0xffffffff {
+				continue // skip coinbase inputs
+			}
+
+			if _, exists := batchTxs[txin.PreviousOutPoint.Hash]; exists {
+				continue
+			}
+
+			if _, exists := spenders[txin.PreviousOutPoint]; !exists {
+				outpoints = append(outpoints, txin.PreviousOutPoint)
+			}
+			spenders[txin.PreviousOutPoint] = append(spenders[txin.PreviousOutPoint], i)
+		}
+	}
+
+	outputs := getRequestedOutputs(ctx, node, outpoints, spenders, errs)
+	for i, err := range errs {
+		if err != nil {
+			errs[i] = errors.Wrap(err, "get outputs")
+		}
+	}
+
+	work := make(chan int)
+	var wait sync.WaitGroup
+	workerCount := runtime.GOMAXPROCS(0)
+	if workerCount > len(txs) {
+		workerCount = len(txs)
+	}
+	for w := 0; w < workerCount; w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for i := range work {
+				errs[i] = promoteBatchTx(ctx, txs[i], batchTxs, outputs, opts)
+			}
+		}()
+	}
+
+	for i := range txs {
+		if errs[i] == nil {
+			work <- i
+		}
+	}
+	close(work)
+	wait.Wait()
+
+	return errs
+}
+
+func promoteBatchTx(ctx context.Context, itx *Transaction, batchTxs map[bitcoin.Hash32]*wire.MsgTx,
+	outputs map[wire.OutPoint]bitcoin.UTXO, opts ParseOptions) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	utxos := make([]bitcoin.UTXO, 0, len(itx.MsgTx.TxIn))
+	for i, txin := range itx.MsgTx.TxIn {
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			continue // skip coinbase inputs
+		}
+
+		if parent, exists := batchTxs[txin.PreviousOutPoint.Hash]; exists {
+			if int(txin.PreviousOutPoint.Index) >= len(parent.TxOut) {
+				return fmt.Errorf("Parent tx output index out of range : input %d: %d >= %d",
+					i, txin.PreviousOutPoint.Index, len(parent.TxOut))
+			}
+
+			output := parent.TxOut[txin.PreviousOutPoint.Index]
+			utxos = append(utxos, bitcoin.UTXO{
+				Hash:          txin.PreviousOutPoint.Hash,
+				Index:         txin.PreviousOutPoint.Index,
+				Value:         output.Value,
+				LockingScript: output.LockingScript,
+			})
+			continue
+		}
+
+		output, exists := outputs[txin.PreviousOutPoint]
+		if !exists {
+			return &OutputNotFoundError{OutPoint: txin.PreviousOutPoint}
+		}
+		utxos = append(utxos, output)
+	}
+
+	if err := itx.PromoteFromUTXOsWithOptions(ctx, utxos, opts); err != nil {
+		return errors.Wrap(err, "promote")
+	}
+
+	return nil
+}
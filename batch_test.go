@@ -0,0 +1,142 @@
+package inspector_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_PromoteBatch(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+	lockingScript := parent.TxOut[0].LockingScript
+
+	// first spends the parent from the node.
+	first := wire.NewMsgTx(1)
+	first.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	first.AddTxOut(wire.NewTxOut(900, lockingScript))
+
+	// second spends first from the batch and the parent from the node again.
+	second := wire.NewMsgTx(1)
+	second.AddTxIn(wire.NewTxIn(wire.NewOutPoint(first.TxHash(), 0), nil))
+	second.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	second.AddTxOut(wire.NewTxOut(800, lockingScript))
+
+	// missing spends an output the node doesn't have.
+	var missingHash bitcoin.Hash32
+	missingHash[0] = 2
+	missing := wire.NewMsgTx(1)
+	missing.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&missingHash, 0), nil))
+	missing.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	missing.AddTxOut(wire.NewTxOut(700, lockingScript))
+
+	var txs []*inspector.Transaction
+	for _, tx := range []*wire.MsgTx{second, missing, first} {
+		itx, err := inspector.NewTransactionFromWire(ctx, tx, true)
+		if err != nil {
+			t.Fatalf("Failed to create transaction : %s", err)
+		}
+		txs = append(txs, itx)
+	}
+
+	errs := inspector.PromoteBatch(ctx, node, txs, inspector.DefaultParseOptions(true))
+
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("Failed to promote : %v, %v", errs[0], errs[2])
+	}
+
+	if _, ok := errors.Cause(errs[1]).(*inspector.OutputNotFoundError); !ok {
+		t.Fatalf("Wrong error for missing output : got %v, want *OutputNotFoundError", errs[1])
+	}
+
+	if !txs[0].IsPromoted(ctx) || !txs[2].IsPromoted(ctx) || txs[1].IsPromoted(ctx) {
+		t.Fatalf("Wrong promoted txs : got %t %t %t, want true false true",
+			txs[0].IsPromoted(ctx), txs[1].IsPromoted(ctx), txs[2].IsPromoted(ctx))
+	}
+
+	if txs[0].Inputs[0].Value != 900 || txs[0].Inputs[1].Value != parent.TxOut[1].Value {
+		t.Fatalf("Wrong input values : got %d %d, want 900 %d", txs[0].Inputs[0].Value,
+			txs[0].Inputs[1].Value, parent.TxOut[1].Value)
+	}
+
+	// The first call includes the missing outpoint and the retry only the parent's outpoints.
+	if calls := node.Calls(); calls.GetOutputs != 2 || calls.OutPoints != 5 {
+		t.Fatalf("Wrong node calls : got %d calls %d outpoints, want 2 calls 5 outpoints",
+			calls.GetOutputs, calls.OutPoints)
+	}
+}
+
+// notFoundNode always returns an OutputNotFoundError for the same outpoint.
+type notFoundNode struct {
+	inspector.NodeInterface
+	outpoint wire.OutPoint
+}
+
+func (n *notFoundNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	return nil, &inspector.OutputNotFoundError{OutPoint: n.outpoint}
+}
+
+func Test_PromoteBatch_NodeErrors(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+	lockingScript := parent.TxOut[0].LockingScript
+
+	var missingHash bitcoin.Hash32
+	missingHash[0] = 2
+	missingOutPoint := wire.NewOutPoint(&missingHash, 0)
+
+	first := wire.NewMsgTx(1)
+	first.AddTxIn(wire.NewTxIn(missingOutPoint, nil))
+	first.AddTxOut(wire.NewTxOut(900, lockingScript))
+
+	second := wire.NewMsgTx(1)
+	second.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	second.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	second.AddTxOut(wire.NewTxOut(800, lockingScript))
+
+	newTxs := func() []*inspector.Transaction {
+		var txs []*inspector.Transaction
+		for _, tx := range []*wire.MsgTx{first, second} {
+			itx, err := inspector.NewTransactionFromWire(ctx, tx, true)
+			if err != nil {
+				t.Fatalf("Failed to create transaction : %s", err)
+			}
+			txs = append(txs, itx)
+		}
+		return txs
+	}
+
+	// The node keeps returning the same not found outpoint after its spender has failed.
+	notFound := &notFoundNode{NodeInterface: node, outpoint: *missingOutPoint}
+	errs := inspector.PromoteBatch(ctx, notFound, newTxs(), inspector.DefaultParseOptions(true))
+	for i, err := range errs {
+		if _, ok := errors.Cause(err).(*inspector.OutputNotFoundError); !ok {
+			t.Fatalf("Wrong error %d : got %v, want *OutputNotFoundError", i, err)
+		}
+	}
+
+	// Outputs returned out of order aren't used.
+	swapped := &mutatingNode{
+		NodeInterface: node,
+		mutate: func(outputs []bitcoin.UTXO) []bitcoin.UTXO {
+			return []bitcoin.UTXO{outputs[1], outputs[0]}
+		},
+	}
+	txs := newTxs()
+	errs = inspector.PromoteBatch(ctx, swapped, txs[1:], inspector.DefaultParseOptions(true))
+	if errs[0] == nil || txs[1].IsPromoted(ctx) {
+		t.Fatalf("Promotion from misordered outputs should fail")
+	}
+}
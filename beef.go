@@ -0,0 +1,467 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/merkle_proof"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// BEEFVersion is the version at the start of a BEEF package as defined by BRC-62.
+	BEEFVersion = uint32(0xEFBE0001)
+)
+
+var (
+	// ErrNotBEEF means the data doesn't start with the BEEF version.
+	ErrNotBEEF = errors.New("Not BEEF")
+)
+
+// BEEF contains the ancestors of a tx from a BEEF package as defined by BRC-62.
+type BEEF struct {
+	// Ancestors are the txs before the subject tx in package order. Each is promoted when the
+	// package contains the parents of all of its inputs. Txs with merkle proofs usually don't.
+	Ancestors []*Transaction
+
+	// Proofs are the merkle proofs of the txs that have merkle paths, keyed by tx hash. They
+	// contain the tx and the merkle root, but not a block hash or header. The block height is
+	// in the merkle path.
+	Proofs map[bitcoin.Hash32]*merkle_proof.MerkleProof
+
+	// Paths are the merkle paths (BUMPs) in the package.
+	Paths []*MerklePath
+
+	pathIndexes map[bitcoin.Hash32]int
+}
+
+// Ancestor returns the ancestor with the specified hash or nil if it isn't in the package.
+func (b *BEEF) Ancestor(hash bitcoin.Hash32) *Transaction {
+	for _, ancestor := range b.Ancestors {
+		if ancestor.Hash.Equal(&hash) {
+			return ancestor
+		}
+	}
+
+	return nil
+}
+
+// MerklePath returns the merkle path for the tx or nil if the package doesn't have one.
+func (b *BEEF) MerklePath(hash bitcoin.Hash32) *MerklePath {
+	index, exists := b.pathIndexes[hash]
+	if !exists {
+		return nil
+	}
+
+	return b.Paths[index]
+}
+
+// NewTransactionFromBEEF builds a promoted ITX from the last tx in a BEEF package. The outputs
+// spent by its inputs are taken from the ancestors in the package, so no node is needed.
+func NewTransactionFromBEEF(ctx context.Context, b []byte,
+	isTest bool) (*Transaction, *BEEF, error) {
+
+	return NewTransactionFromBEEFWithOptions(ctx, b, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromBEEFWithOptions builds a promoted ITX from the last tx in a BEEF package.
+func NewTransactionFromBEEFWithOptions(ctx context.Context, b []byte,
+	opts ParseOptions) (*Transaction, *BEEF, error) {
+
+	limits := opts.ReadLimits.orDefault()
+	if uint64(len(b)) > limits.MaxSize {
+		return nil, nil, errors.Wrapf(ErrSizeLimit, "%d > %d", len(b), limits.MaxSize)
+	}
+
+	r := bytes.NewReader(b)
+	txs, pathIndexes, paths, err := readBEEF(r, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.Len() != 0 {
+		return nil, nil, fmt.Errorf("Extra BEEF data : %d bytes", r.Len())
+	}
+
+	if len(txs) == 0 {
+		return nil, nil, errors.New("Empty BEEF")
+	}
+
+	beef := &BEEF{
+		Proofs:      make(map[bitcoin.Hash32]*merkle_proof.MerkleProof),
+		Paths:       paths,
+		pathIndexes: make(map[bitcoin.Hash32]int),
+	}
+
+	packageTxs := make(map[bitcoin.Hash32]*wire.MsgTx)
+	var result *Transaction
+	for i, tx := range txs {
+		hash := *tx.TxHash()
+		isSubject := i == len(txs)-1
+
+		if pathIndex, exists := pathIndexes[i]; exists {
+			proof, err := paths[pathIndex].MerkleProof(hash)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "tx %d proof", i)
+			}
+			proof.Tx = tx
+
+			beef.Proofs[hash] = proof
+			beef.pathIndexes[hash] = pathIndex
+		}
+
+		itx, err := NewTransactionFromHashWireWithOptions(ctx, hash, tx, opts)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "tx %d", i)
+		}
+
+		utxos, parents, err := packageUTXOs(tx, packageTxs)
+		if err == nil {
+			if err := itx.PromoteFromUTXOsWithOptions(ctx, utxos, opts); err != nil {
+				return nil, nil, errors.Wrapf(err, "promote tx %d", i)
+			}
+
+			for j, input := range itx.Inputs {
+				input.ParentTx = parents[j]
+			}
+		} else if isSubject {
+			return nil, nil, errors.Wrap(err, "promote")
+		}
+
+		packageTxs[hash] = tx
+		if isSubject {
+			result = itx
+		} else {
+			beef.Ancestors = append(beef.Ancestors, itx)
+		}
+	}
+
+	return result, beef, nil
+}
+
+// packageUTXOs returns the outputs spent by the tx and the parent tx of each input. It returns
+// ErrMissingParentTx if the parent of an input isn't in the package.
+func packageUTXOs(tx *wire.MsgTx,
+	packageTxs map[bitcoin.Hash32]*wire.MsgTx) ([]bitcoin.UTXO, []*wire.MsgTx, error) {
+
+	utxos := make([]bitcoin.UTXO, 0, len(tx.TxIn))
+	parents := make([]*wire.MsgTx, len(tx.TxIn))
+	for i, txin := range tx.TxIn {
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			continue // skip coinbase inputs
+		}
+
+		parent, exists := packageTxs[txin.PreviousOutPoint.Hash]
+		if !exists {
+			return nil, nil, errors.Wrapf(ErrMissingParentTx, "input %d: %s", i,
+				txin.PreviousOutPoint.Hash)
+		}
+
+		if int(txin.PreviousOutPoint.Index) >= len(parent.TxOut) {
+			return nil, nil, fmt.Errorf("Parent tx output index out of range : input %d: %d >= %d",
+				i, txin.PreviousOutPoint.Index, len(parent.TxOut))
+		}
+
+		output := parent.TxOut[txin.PreviousOutPoint.Index]
+		utxos = append(utxos, bitcoin.UTXO{
+			Hash:          txin.PreviousOutPoint.Hash,
+			Index:         txin.PreviousOutPoint.Index,
+			Value:         output.Value,
+			LockingScript: output.LockingScript,
+		})
+		parents[i] = parent
+	}
+
+	return utxos, parents, nil
+}
+
+// readBEEF reads the txs of a BEEF package and the index of the merkle path of each tx that has
+// one.
+func readBEEF(r io.Reader, limits ReadLimits) ([]*wire.MsgTx, map[int]int, []*MerklePath,
+	error) {
+
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, nil, nil, errors.Wrap(err, "version")
+	}
+	if version != BEEFVersion {
+		return nil, nil, nil, errors.Wrapf(ErrNotBEEF, "version 0x%08x", version)
+	}
+
+	pathCount, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "path count")
+	}
+
+	var paths []*MerklePath
+	for i := uint64(0); i < pathCount; i++ {
+		path := &MerklePath{}
+		if err := path.Deserialize(r); err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "path %d", i)
+		}
+		paths = append(paths, path)
+	}
+
+	txCount, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "tx count")
+	}
+
+	var txs []*wire.MsgTx
+	pathIndexes := make(map[int]int)
+	for i := uint64(0); i < txCount; i++ {
+		tx, err := readMsgTx(r, limits)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "tx %d", i)
+		}
+
+		var hasPath [1]byte
+		if _, err := io.ReadFull(r, hasPath[:]); err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "tx %d has path", i)
+		}
+
+		switch hasPath[0] {
+		case 0:
+		case 1:
+			pathIndex, err := wire.ReadVarInt(r, 0)
+			if err != nil {
+				return nil, nil, nil, errors.Wrapf(err, "tx %d path index", i)
+			}
+			if pathIndex >= uint64(len(paths)) {
+				return nil, nil, nil, fmt.Errorf("Path index out of range : tx %d: %d >= %d", i,
+					pathIndex, len(paths))
+			}
+			pathIndexes[len(txs)] = int(pathIndex)
+		default:
+			return nil, nil, nil, fmt.Errorf("Invalid has path flag : tx %d: 0x%02x", i,
+				hasPath[0])
+		}
+
+		txs = append(txs, tx)
+	}
+
+	return txs, pathIndexes, paths, nil
+}
+
+// BEEFSource provides the ancestors written to BEEF packages.
+type BEEFSource interface {
+	GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error)
+
+	// GetMerklePath returns the merkle path of a tx in a block, or nil if the tx isn't in a block.
+	GetMerklePath(ctx context.Context, hash bitcoin.Hash32) (*MerklePath, error)
+}
+
+// MerklePathNode is a NodeInterface that also provides merkle paths, so it can be used as a
+// BEEFSource.
+type MerklePathNode interface {
+	NodeInterface
+	GetMerklePath(ctx context.Context, hash bitcoin.Hash32) (*MerklePath, error)
+}
+
+// Ancestor is a tx and its merkle path if it is in a block.
+type Ancestor struct {
+	Tx         *wire.MsgTx
+	MerklePath *MerklePath
+}
+
+// Ancestors is a BEEFSource built from supplied txs keyed by tx hash.
+type Ancestors map[bitcoin.Hash32]*Ancestor
+
+func (a Ancestors) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	ancestor, exists := a[hash]
+	if !exists || ancestor.Tx == nil {
+		return nil, errors.Wrap(ErrTxNotFound, hash.String())
+	}
+
+	return ancestor.Tx, nil
+}
+
+func (a Ancestors) GetMerklePath(ctx context.Context,
+	hash bitcoin.Hash32) (*MerklePath, error) {
+
+	ancestor, exists := a[hash]
+	if !exists {
+		return nil, nil
+	}
+
+	return ancestor.MerklePath, nil
+}
+
+// GetTx returns an ancestor from the package so a BEEF can be used as a BEEFSource.
+func (b *BEEF) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	ancestor := b.Ancestor(hash)
+	if ancestor == nil {
+		return nil, errors.Wrap(ErrTxNotFound, hash.String())
+	}
+
+	return ancestor.MsgTx, nil
+}
+
+// GetMerklePath returns the merkle path of an ancestor from the package.
+func (b *BEEF) GetMerklePath(ctx context.Context, hash bitcoin.Hash32) (*MerklePath, error) {
+	return b.MerklePath(hash), nil
+}
+
+// WriteBEEF writes the tx as a BEEF package as defined by BRC-62. The package contains each
+// ancestor back to those in blocks, with their merkle paths, so the receiver can promote and
+// verify the tx without a node. The parents of inputs are taken from the inputs' ParentTx when set
+// and otherwise from the source.
+func (itx *Transaction) WriteBEEF(ctx context.Context, w io.Writer, source BEEFSource) error {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	writer := &beefWriter{
+		source:      source,
+		added:       make(map[bitcoin.Hash32]bool),
+		pathIndexes: make(map[bitcoin.Hash32]int),
+		heights:     make(map[uint64]int),
+	}
+
+	for i, txin := range itx.MsgTx.TxIn {
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			continue // skip coinbase inputs
+		}
+
+		var parentTx *wire.MsgTx
+		if i < len(itx.Inputs) {
+			parentTx = itx.Inputs[i].ParentTx
+		}
+
+		if err := writer.addAncestor(ctx, txin.PreviousOutPoint.Hash, parentTx); err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+	}
+
+	writer.txs = append(writer.txs, itx.MsgTx)
+
+	return writer.write(w)
+}
+
+type beefWriter struct {
+	source BEEFSource
+
+	txs         []*wire.MsgTx // parents before children
+	added       map[bitcoin.Hash32]bool
+	paths       []*MerklePath
+	pathIndexes map[bitcoin.Hash32]int // tx hash to path index
+	heights     map[uint64]int         // block height to path index
+}
+
+// addAncestor adds the tx after its ancestors. Ancestors of txs in blocks aren't needed.
+func (bw *beefWriter) addAncestor(ctx context.Context, hash bitcoin.Hash32,
+	tx *wire.MsgTx) error {
+
+	if bw.added[hash] {
+		return nil
+	}
+
+	if tx == nil {
+		var err error
+		tx, err = bw.source.GetTx(ctx, hash)
+		if err != nil {
+			return errors.Wrapf(err, "get tx %s", hash)
+		}
+	}
+
+	if !tx.TxHash().Equal(&hash) {
+		return fmt.Errorf("Wrong tx hash : got %s, want %s", tx.TxHash(), hash)
+	}
+
+	path, err := bw.source.GetMerklePath(ctx, hash)
+	if err != nil {
+		return errors.Wrapf(err, "get merkle path %s", hash)
+	}
+
+	if path != nil {
+		if err := bw.addPath(hash, path); err != nil {
+			return errors.Wrapf(err, "merkle path %s", hash)
+		}
+	} else {
+		for i, txin := range tx.TxIn {
+			if txin.PreviousOutPoint.Index == 0xffffffff {
+				continue // skip coinbase inputs
+			}
+
+			if err := bw.addAncestor(ctx, txin.PreviousOutPoint.Hash, nil); err != nil {
+				return errors.Wrapf(err, "%s input %d", hash, i)
+			}
+		}
+	}
+
+	bw.added[hash] = true
+	bw.txs = append(bw.txs, tx)
+	return nil
+}
+
+// addPath adds the merkle path for the tx. Paths for the same block are combined into one.
+func (bw *beefWriter) addPath(hash bitcoin.Hash32, path *MerklePath) error {
+	if !path.Contains(hash) {
+		return errors.Wrap(ErrInvalidMerklePath, "missing tx")
+	}
+
+	index, exists := bw.heights[path.BlockHeight]
+	if !exists {
+		index = len(bw.paths)
+		bw.paths = append(bw.paths, path)
+		bw.heights[path.BlockHeight] = index
+	} else {
+		combined, err := bw.paths[index].combine(path)
+		if err != nil {
+			return errors.Wrap(err, "combine")
+		}
+		bw.paths[index] = combined
+	}
+
+	bw.pathIndexes[hash] = index
+	return nil
+}
+
+func (bw *beefWriter) write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, BEEFVersion); err != nil {
+		return errors.Wrap(err, "version")
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(bw.paths))); err != nil {
+		return errors.Wrap(err, "path count")
+	}
+
+	for i, path := range bw.paths {
+		if err := path.Serialize(w); err != nil {
+			return errors.Wrapf(err, "path %d", i)
+		}
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(bw.txs))); err != nil {
+		return errors.Wrap(err, "tx count")
+	}
+
+	for i, tx := range bw.txs {
+		if err := tx.Serialize(w); err != nil {
+			return errors.Wrapf(err, "tx %d", i)
+		}
+
+		pathIndex, hasPath := bw.pathIndexes[*tx.TxHash()]
+		if !hasPath {
+			if _, err := w.Write([]byte{0}); err != nil {
+				return errors.Wrapf(err, "tx %d has path", i)
+			}
+			continue
+		}
+
+		if _, err := w.Write([]byte{1}); err != nil {
+			return errors.Wrapf(err, "tx %d has path", i)
+		}
+
+		if err := wire.WriteVarInt(w, 0, uint64(pathIndex)); err != nil {
+			return errors.Wrapf(err, "tx %d path index", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,436 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_MerklePath(t *testing.T) {
+	var h0, h1, txid bitcoin.Hash32
+	rand.Read(h0[:])
+	rand.Read(h1[:])
+	rand.Read(txid[:])
+
+	// Block with 3 txs where the last is duplicated to complete the first level.
+	wantRoot := merkleParent(merkleParent(h0, h1), merkleParent(txid, txid))
+
+	paths := []MerklePath{
+		{
+			BlockHeight: 1000,
+			Levels: [][]MerklePathLeaf{
+				{{Offset: 2, Hash: txid, IsTxID: true}, {Offset: 3, IsDuplicate: true}},
+				{{Offset: 0, Hash: merkleParent(h0, h1)}},
+			},
+		},
+		{
+			// The second level is calculated from the first.
+			BlockHeight: 1000,
+			Levels: [][]MerklePathLeaf{
+				{{Offset: 0, Hash: h0}, {Offset: 1, Hash: h1},
+					{Offset: 2, Hash: txid, IsTxID: true}, {Offset: 3, IsDuplicate: true}},
+				{},
+			},
+		},
+	}
+
+	for i, path := range paths {
+		buf := &bytes.Buffer{}
+		if err := path.Serialize(buf); err != nil {
+			t.Fatalf("Failed to serialize path %d : %s", i, err)
+		}
+
+		var read MerklePath
+		if err := read.Deserialize(buf); err != nil {
+			t.Fatalf("Failed to deserialize path %d : %s", i, err)
+		}
+
+		if read.BlockHeight != path.BlockHeight || len(read.Levels) != len(path.Levels) {
+			t.Fatalf("Wrong path %d : got %+v, want %+v", i, read, path)
+		}
+		for l := range path.Levels {
+			if len(read.Levels[l]) != len(path.Levels[l]) {
+				t.Fatalf("Wrong path %d level %d : got %+v, want %+v", i, l, read.Levels[l],
+					path.Levels[l])
+			}
+			for j := range path.Levels[l] {
+				if read.Levels[l][j] != path.Levels[l][j] {
+					t.Fatalf("Wrong path %d level %d leaf %d : got %+v, want %+v", i, l, j,
+						read.Levels[l][j], path.Levels[l][j])
+				}
+			}
+		}
+
+		proof, err := read.MerkleProof(txid)
+		if err != nil {
+			t.Fatalf("Failed to get proof %d : %s", i, err)
+		}
+
+		if !proof.MerkleRoot.Equal(&wantRoot) {
+			t.Fatalf("Wrong path %d root : got %s, want %s", i, proof.MerkleRoot, wantRoot)
+		}
+	}
+
+	if _, err := paths[0].MerkleProof(h0); errors.Cause(err) != ErrInvalidMerklePath {
+		t.Fatalf("Wrong error for missing tx : got %v, want %s", err, ErrInvalidMerklePath)
+	}
+}
+
+func Test_NewTransactionFromBEEF(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxOut(wire.NewTxOut(900, parent.TxOut[0].LockingScript))
+
+	var other bitcoin.Hash32
+	rand.Read(other[:])
+	path := &MerklePath{
+		BlockHeight: 1000,
+		Levels: [][]MerklePathLeaf{
+			{{Offset: 0, Hash: other}, {Offset: 1, Hash: *parent.TxHash(), IsTxID: true}},
+		},
+	}
+
+	b := writeTestBEEF(t, []*MerklePath{path}, []*wire.MsgTx{parent, tx}, map[int]int{0: 0})
+
+	itx, beef, err := NewTransactionFromBEEF(ctx, b, true)
+	if err != nil {
+		t.Fatalf("Failed to read BEEF : %s", err)
+	}
+
+	if !itx.Hash.Equal(tx.TxHash()) {
+		t.Fatalf("Wrong tx hash : got %s, want %s", itx.Hash, tx.TxHash())
+	}
+
+	if !itx.IsPromoted(ctx) {
+		t.Fatalf("Not promoted")
+	}
+
+	if itx.Inputs[0].Value != 1000 {
+		t.Fatalf("Wrong input value : got %d, want %d", itx.Inputs[0].Value, 1000)
+	}
+
+	if itx.Inputs[0].ParentTx == nil ||
+		!itx.Inputs[0].ParentTx.TxHash().Equal(parent.TxHash()) {
+		t.Fatalf("Wrong input parent tx")
+	}
+
+	if len(beef.Ancestors) != 1 || beef.Ancestor(*parent.TxHash()) == nil {
+		t.Fatalf("Missing ancestor")
+	}
+
+	proof, exists := beef.Proofs[*parent.TxHash()]
+	if !exists {
+		t.Fatalf("Missing proof")
+	}
+
+	wantRoot := merkleParent(other, *parent.TxHash())
+	if !proof.MerkleRoot.Equal(&wantRoot) {
+		t.Fatalf("Wrong proof root : got %s, want %s", proof.MerkleRoot, wantRoot)
+	}
+
+	if beef.MerklePath(*parent.TxHash()) != beef.Paths[0] {
+		t.Fatalf("Wrong merkle path")
+	}
+
+	// The subject can't be promoted without its parent.
+	b = writeTestBEEF(t, nil, []*wire.MsgTx{tx}, nil)
+	if _, _, err := NewTransactionFromBEEF(ctx, b, true); errors.Cause(err) !=
+		ErrMissingParentTx {
+		t.Fatalf("Wrong error for missing parent : got %v, want %s", err, ErrMissingParentTx)
+	}
+
+	buf := &bytes.Buffer{}
+	tx.Serialize(buf)
+	if _, _, err := NewTransactionFromBEEF(ctx, buf.Bytes(), true); errors.Cause(err) !=
+		ErrNotBEEF {
+		t.Fatalf("Wrong error for raw tx : got %v, want %s", err, ErrNotBEEF)
+	}
+}
+
+// Merkle path vectors built from mainnet blocks. The tx hashes and merkle roots are from the
+// blocks so the roots calculated from the paths can be checked against the block headers.
+var merklePathVectors = []struct {
+	name string
+	hex  string
+	txid string
+	root string
+}{
+	{
+		// Block 170 containing the first tx between people. Both txs are in the path.
+		name: "block 170",
+		hex: "aa0102000082501c1178fa0b222c1f3d474ec726b832013f0a532b44bb620cce8624a5feb10102169e" +
+			"1e83e930853391bc6f35f605c6754cfead57cf8387639d3b4096c54f18f4",
+		txid: "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16",
+		root: "7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff",
+	},
+	{
+		// Block 100000 with a path for its third tx.
+		name: "block 100000",
+		hex: "fea086010002020202c46e239ab7d28e2c019b6d66ad8fae98a56ef1f21aeecb94d1b1718186f05963" +
+			"03001d0cb83721529a062d9675b98d6e5c587e4a770fc84ed00abc5a5de04568a6e901000015b88c5107" +
+			"195bf09eb9da89b83d95b3d070079a3c5c5d3d17d0dcd873fbdacc",
+		txid: "6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		root: "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766",
+	},
+}
+
+func Test_MerklePath_Vectors(t *testing.T) {
+	for _, tt := range merklePathVectors {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.hex)
+			if err != nil {
+				t.Fatalf("Failed to decode hex : %s", err)
+			}
+
+			var path MerklePath
+			if err := path.Deserialize(bytes.NewReader(b)); err != nil {
+				t.Fatalf("Failed to deserialize path : %s", err)
+			}
+
+			txid, _ := bitcoin.NewHash32FromStr(tt.txid)
+			wantRoot, _ := bitcoin.NewHash32FromStr(tt.root)
+
+			if !path.Contains(*txid) {
+				t.Fatalf("Path doesn't contain tx %s", txid)
+			}
+
+			proof, err := path.MerkleProof(*txid)
+			if err != nil {
+				t.Fatalf("Failed to get proof : %s", err)
+			}
+
+			if !proof.MerkleRoot.Equal(wantRoot) {
+				t.Fatalf("Wrong root : got %s, want %s", proof.MerkleRoot, wantRoot)
+			}
+
+			buf := &bytes.Buffer{}
+			if err := path.Serialize(buf); err != nil {
+				t.Fatalf("Failed to serialize path : %s", err)
+			}
+
+			if !bytes.Equal(buf.Bytes(), b) {
+				t.Fatalf("Wrong serialized path : got %x, want %x", buf.Bytes(), b)
+			}
+		})
+	}
+}
+
+// beefVector is a BEEF containing the tx from block 170, with a merkle path for block 170, and an
+// unconfirmed tx spending its first output.
+var beefVector = "0100beef01aa0102000082501c1178fa0b222c1f3d474ec726b832013f0a532b44bb620cce8624" +
+	"a5feb10102169e1e83e930853391bc6f35f605c6754cfead57cf8387639d3b4096c54f18f4020100000001c997a5" +
+	"e56e104102fa209c6a852dd90660a20b2d9c352423edce25857fcd3704000000004847304402204e45e16932b8af" +
+	"514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd410220181522ec8eca07de4860a4acdd12909d831cc5" +
+	"6cbbac4622082221a8768d1d0901ffffffff0200ca9a3b00000000434104ae1a62fe09c5f51b13905f07f06b99a2" +
+	"f7159b2225f374cd378d71302fa28414e7aab37397f554a7df5f142c21c1b7303b8a0626f1baded5c72a704f7e6c" +
+	"d84cac00286bee0000000043410411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5c" +
+	"b2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3ac0000000001000100000001169e" +
+	"1e83e930853391bc6f35f605c6754cfead57cf8387639d3b4096c54f18f40000000000ffffffff01e80300000000" +
+	"000002006a0000000000"
+
+func Test_NewTransactionFromBEEF_Vector(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := hex.DecodeString(beefVector)
+	if err != nil {
+		t.Fatalf("Failed to decode hex : %s", err)
+	}
+
+	itx, beef, err := NewTransactionFromBEEF(ctx, b, false)
+	if err != nil {
+		t.Fatalf("Failed to read BEEF : %s", err)
+	}
+
+	wantHash, _ := bitcoin.NewHash32FromStr(
+		"80e68070e0636ea3f6a29fdc9518a54ec43156838c2502b3413c1e6f9571fdb7")
+	if !itx.Hash.Equal(wantHash) {
+		t.Fatalf("Wrong tx hash : got %s, want %s", itx.Hash, wantHash)
+	}
+
+	// The first output of the block 170 tx is 10 bitcoin.
+	if itx.Inputs[0].Value != 1000000000 {
+		t.Fatalf("Wrong input value : got %d, want %d", itx.Inputs[0].Value, 1000000000)
+	}
+
+	ancestorHash, _ := bitcoin.NewHash32FromStr(
+		"f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16")
+	if len(beef.Ancestors) != 1 || beef.Ancestor(*ancestorHash) == nil {
+		t.Fatalf("Missing ancestor %s", ancestorHash)
+	}
+
+	proof, exists := beef.Proofs[*ancestorHash]
+	if !exists {
+		t.Fatalf("Missing proof")
+	}
+
+	wantRoot, _ := bitcoin.NewHash32FromStr(
+		"7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff")
+	if !proof.MerkleRoot.Equal(wantRoot) {
+		t.Fatalf("Wrong proof root : got %s, want %s", proof.MerkleRoot, wantRoot)
+	}
+
+	if beef.Paths[0].BlockHeight != 170 {
+		t.Fatalf("Wrong block height : got %d, want %d", beef.Paths[0].BlockHeight, 170)
+	}
+}
+
+func writeTestBEEF(t *testing.T, paths []*MerklePath, txs []*wire.MsgTx,
+	pathIndexes map[int]int) []byte {
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, BEEFVersion)
+
+	wire.WriteVarInt(buf, 0, uint64(len(paths)))
+	for _, path := range paths {
+		if err := path.Serialize(buf); err != nil {
+			t.Fatalf("Failed to serialize path : %s", err)
+		}
+	}
+
+	wire.WriteVarInt(buf, 0, uint64(len(txs)))
+	for i, tx := range txs {
+		if err := tx.Serialize(buf); err != nil {
+			t.Fatalf("Failed to serialize tx : %s", err)
+		}
+
+		if pathIndex, exists := pathIndexes[i]; exists {
+			buf.WriteByte(1)
+			wire.WriteVarInt(buf, 0, uint64(pathIndex))
+		} else {
+			buf.WriteByte(0)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func Test_WriteBEEF(t *testing.T) {
+	ctx := context.Background()
+
+	// Two confirmed txs in the same block fund an unconfirmed parent of the tx.
+	funding1 := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+	funding2 := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 2})
+
+	var other bitcoin.Hash32
+	rand.Read(other[:])
+	path1 := &MerklePath{
+		BlockHeight: 1000,
+		Levels: [][]MerklePathLeaf{
+			{{Offset: 0, Hash: *funding1.TxHash(), IsTxID: true},
+				{Offset: 1, Hash: *funding2.TxHash()}},
+			{{Offset: 1, Hash: other}},
+		},
+	}
+	path2 := &MerklePath{
+		BlockHeight: 1000,
+		Levels: [][]MerklePathLeaf{
+			{{Offset: 0, Hash: *funding1.TxHash()},
+				{Offset: 1, Hash: *funding2.TxHash(), IsTxID: true}},
+			{{Offset: 1, Hash: other}},
+		},
+	}
+
+	parent := wire.NewMsgTx(1)
+	parent.AddTxIn(wire.NewTxIn(wire.NewOutPoint(funding1.TxHash(), 0), nil))
+	parent.AddTxIn(wire.NewTxIn(wire.NewOutPoint(funding2.TxHash(), 0), nil))
+	parent.AddTxOut(wire.NewTxOut(1900, funding1.TxOut[0].LockingScript))
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxOut(wire.NewTxOut(1800, funding1.TxOut[0].LockingScript))
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	ancestors := Ancestors{
+		*funding1.TxHash(): {Tx: funding1, MerklePath: path1},
+		*funding2.TxHash(): {Tx: funding2, MerklePath: path2},
+		*parent.TxHash():   {Tx: parent},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.WriteBEEF(ctx, buf, ancestors); err != nil {
+		t.Fatalf("Failed to write BEEF : %s", err)
+	}
+	b := buf.Bytes()
+
+	read, beef, err := NewTransactionFromBEEF(ctx, b, true)
+	if err != nil {
+		t.Fatalf("Failed to read BEEF : %s", err)
+	}
+
+	if !read.Hash.Equal(&itx.Hash) {
+		t.Fatalf("Wrong tx hash : got %s, want %s", read.Hash, itx.Hash)
+	}
+
+	if read.Inputs[0].Value != 1900 {
+		t.Fatalf("Wrong input value : got %d, want %d", read.Inputs[0].Value, 1900)
+	}
+
+	if len(beef.Ancestors) != 3 {
+		t.Fatalf("Wrong ancestor count : got %d, want %d", len(beef.Ancestors), 3)
+	}
+
+	// Paths for the same block are combined.
+	if len(beef.Paths) != 1 || len(beef.Proofs) != 2 {
+		t.Fatalf("Wrong path count : got %d paths %d proofs, want 1 path 2 proofs",
+			len(beef.Paths), len(beef.Proofs))
+	}
+
+	if !beef.Ancestor(*parent.TxHash()).IsPromoted(ctx) {
+		t.Fatalf("Parent not promoted")
+	}
+
+	// A package can be written again from itself.
+	buf = &bytes.Buffer{}
+	if err := read.WriteBEEF(ctx, buf, beef); err != nil {
+		t.Fatalf("Failed to write BEEF : %s", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Fatalf("Wrong rewritten BEEF : got %x, want %x", buf.Bytes(), b)
+	}
+
+	delete(ancestors, *parent.TxHash())
+	if err := itx.WriteBEEF(ctx, &bytes.Buffer{}, ancestors); errors.Cause(err) !=
+		ErrTxNotFound {
+		t.Fatalf("Wrong error for missing ancestor : got %v, want %s", err, ErrTxNotFound)
+	}
+}
+
+func Test_WriteBEEF_Vector(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := hex.DecodeString(beefVector)
+	if err != nil {
+		t.Fatalf("Failed to decode hex : %s", err)
+	}
+
+	itx, beef, err := NewTransactionFromBEEF(ctx, b, false)
+	if err != nil {
+		t.Fatalf("Failed to read BEEF : %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.WriteBEEF(ctx, buf, beef); err != nil {
+		t.Fatalf("Failed to write BEEF : %s", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Fatalf("Wrong BEEF : got %x, want %x", buf.Bytes(), b)
+	}
+}
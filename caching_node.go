@@ -0,0 +1,244 @@
+package inspector
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+)
+
+const (
+	// cacheEntryOverhead is the approximate memory used by a cache entry in addition to its data.
+	cacheEntryOverhead = 128
+)
+
+// CachingNode wraps a NodeInterface and caches the txs and outputs it returns in a least recently
+// used cache that is bounded by the number of entries and by the total size of the entries.
+// Outputs are also served from cached txs. Returned txs are shared with the cache and must not be
+// modified.
+type CachingNode struct {
+	node NodeInterface
+
+	maxEntries int
+	maxBytes   uint64
+
+	// lru contains *cacheEntry with the most recently used at the front.
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+	bytes   uint64
+
+	stats CacheStats
+
+	lock sync.Mutex
+}
+
+// CacheStats are the counters of a CachingNode.
+type CacheStats struct {
+	TxHits       uint64
+	TxMisses     uint64
+	OutputHits   uint64
+	OutputMisses uint64
+	Evictions    uint64
+
+	Entries int
+	Bytes   uint64
+}
+
+// cacheKey identifies a tx when index is txKeyIndex and an output otherwise.
+type cacheKey struct {
+	hash  bitcoin.Hash32
+	index uint32
+}
+
+const txKeyIndex = ^uint32(0)
+
+type cacheEntry struct {
+	key    cacheKey
+	tx     *wire.MsgTx
+	output *bitcoin.UTXO
+	size   uint64
+}
+
+// NewCachingNode creates a CachingNode. A zero maxEntries or maxBytes means that limit isn't
+// applied.
+func NewCachingNode(node NodeInterface, maxEntries int, maxBytes uint64) *CachingNode {
+	return &CachingNode{
+		node:       node,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		lru:        list.New(),
+		entries:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// Stats returns the current counters.
+func (n *CachingNode) Stats() CacheStats {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	result := n.stats
+	result.Entries = n.lru.Len()
+	result.Bytes = n.bytes
+	return result
+}
+
+// SaveTx saves the tx to the wrapped node and then adds it to the cache.
+func (n *CachingNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	if err := n.node.SaveTx(ctx, tx); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	n.addTx(*tx.TxHash(), tx)
+	n.lock.Unlock()
+
+	return nil
+}
+
+func (n *CachingNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	n.lock.Lock()
+	if tx := n.getTx(hash); tx != nil {
+		n.stats.TxHits++
+		n.lock.Unlock()
+		return tx, nil
+	}
+	n.stats.TxMisses++
+	n.lock.Unlock()
+
+	tx, err := n.node.GetTx(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tx.TxHash().Equal(&hash) {
+		return nil, fmt.Errorf("Wrong tx hash : got %s, want %s", tx.TxHash(), hash)
+	}
+
+	n.lock.Lock()
+	n.addTx(hash, tx)
+	n.lock.Unlock()
+
+	return tx, nil
+}
+
+// GetOutputs returns cached outputs and requests only the missing outputs from the wrapped node.
+func (n *CachingNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	results := make([]bitcoin.UTXO, len(outpoints))
+	var missing []wire.OutPoint
+	var missingIndexes []int
+
+	n.lock.Lock()
+	for i, outpoint := range outpoints {
+		if output := n.getOutput(outpoint); output != nil {
+			n.stats.OutputHits++
+			results[i] = *output
+			continue
+		}
+
+		n.stats.OutputMisses++
+		missing = append(missing, outpoint)
+		missingIndexes = append(missingIndexes, i)
+	}
+	n.lock.Unlock()
+
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	outputs, err := n.node.GetOutputs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't cache outputs under outpoints they weren't returned for.
+	if err := verifyOutputs(missing, outputs); err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for i, output := range outputs {
+		results[missingIndexes[i]] = output
+		n.addOutput(output)
+	}
+
+	return results, nil
+}
+
+func (n *CachingNode) getTx(hash bitcoin.Hash32) *wire.MsgTx {
+	element, exists := n.entries[cacheKey{hash: hash, index: txKeyIndex}]
+	if !exists {
+		return nil
+	}
+
+	n.lru.MoveToFront(element)
+	return element.Value.(*cacheEntry).tx
+}
+
+// getOutput returns the output from the output cache or from a cached tx.
+func (n *CachingNode) getOutput(outpoint wire.OutPoint) *bitcoin.UTXO {
+	if element, exists := n.entries[cacheKey{hash: outpoint.Hash,
+		index: outpoint.Index}]; exists {
+		n.lru.MoveToFront(element)
+		return element.Value.(*cacheEntry).output
+	}
+
+	tx := n.getTx(outpoint.Hash)
+	if tx == nil || int(outpoint.Index) >= len(tx.TxOut) {
+		return nil
+	}
+
+	return &bitcoin.UTXO{
+		Hash:          outpoint.Hash,
+		Index:         outpoint.Index,
+		Value:         tx.TxOut[outpoint.Index].Value,
+		LockingScript: tx.TxOut[outpoint.Index].LockingScript,
+	}
+}
+
+func (n *CachingNode) addTx(hash bitcoin.Hash32, tx *wire.MsgTx) {
+	n.add(&cacheEntry{
+		key:  cacheKey{hash: hash, index: txKeyIndex},
+		tx:   tx,
+		size: uint64(tx.SerializeSize()) + cacheEntryOverhead,
+	})
+}
+
+func (n *CachingNode) addOutput(output bitcoin.UTXO) {
+	n.add(&cacheEntry{
+		key:    cacheKey{hash: output.Hash, index: output.Index},
+		output: &output,
+		size:   uint64(bitcoin.Hash32Size+4+8+len(output.LockingScript)) + cacheEntryOverhead,
+	})
+}
+
+func (n *CachingNode) add(entry *cacheEntry) {
+	if n.maxBytes != 0 && entry.size > n.maxBytes {
+		return // would evict everything and still not fit
+	}
+
+	if element, exists := n.entries[entry.key]; exists {
+		n.remove(element)
+	}
+
+	n.entries[entry.key] = n.lru.PushFront(entry)
+	n.bytes += entry.size
+
+	for (n.maxEntries != 0 && n.lru.Len() > n.maxEntries) ||
+		(n.maxBytes != 0 && n.bytes > n.maxBytes) {
+		n.remove(n.lru.Back())
+		n.stats.Evictions++
+	}
+}
+
+func (n *CachingNode) remove(element *list.Element) {
+	entry := n.lru.Remove(element).(*cacheEntry)
+	delete(n.entries, entry.key)
+	n.bytes -= entry.size
+}
@@ -0,0 +1,200 @@
+package inspector_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+)
+
+func Test_CachingNode(t *testing.T) {
+	ctx := context.Background()
+
+	node := inspectortest.NewNode()
+	var parents []*wire.MsgTx
+	for i := 0; i < 3; i++ {
+		parent := newParentTx(t)
+		node.AddTx(parent)
+		parents = append(parents, parent)
+	}
+
+	cache := inspector.NewCachingNode(node, 0, 0)
+
+	outpoints := []wire.OutPoint{
+		{Hash: *parents[0].TxHash(), Index: 0},
+		{Hash: *parents[1].TxHash(), Index: 0},
+	}
+	if _, err := cache.GetOutputs(ctx, outpoints); err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+
+	// First outpoint is cached, so only the second is requested.
+	outpoints = append(outpoints, wire.OutPoint{Hash: *parents[2].TxHash(), Index: 0})
+	outputs, err := cache.GetOutputs(ctx, outpoints[1:])
+	if err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+
+	if calls := node.Calls(); calls.GetOutputs != 2 || calls.OutPoints != 3 {
+		t.Fatalf("Wrong underlying calls : got %d calls %d outpoints, want 2 calls 3 outpoints",
+			calls.GetOutputs, calls.OutPoints)
+	}
+
+	for i, output := range outputs {
+		if !output.Hash.Equal(&outpoints[i+1].Hash) {
+			t.Fatalf("Wrong output %d hash : got %s, want %s", i, output.Hash,
+				outpoints[i+1].Hash)
+		}
+	}
+
+	// Txs are cached and their outputs are served from them.
+	if _, err := cache.GetTx(ctx, *parents[0].TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if _, err := cache.GetTx(ctx, *parents[0].TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if _, err := cache.GetOutputs(ctx, []wire.OutPoint{{Hash: *parents[0].TxHash(),
+		Index: 1}}); err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+
+	if calls := node.Calls(); calls.GetTx != 1 || calls.GetOutputs != 2 {
+		t.Fatalf("Wrong underlying calls : got %d GetTx %d GetOutputs, want 1 and 2",
+			calls.GetTx, calls.GetOutputs)
+	}
+
+	// Write through
+	child := newParentTx(t)
+	if err := cache.SaveTx(ctx, child); err != nil {
+		t.Fatalf("Failed to save tx : %s", err)
+	}
+	if calls := node.Calls(); calls.SaveTx != 1 {
+		t.Fatalf("Saved tx not written through")
+	}
+	if _, err := cache.GetTx(ctx, *child.TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+
+	stats := cache.Stats()
+	t.Logf("Stats : %+v", stats)
+
+	if stats.TxHits != 2 || stats.TxMisses != 1 {
+		t.Fatalf("Wrong tx stats : got %d hits %d misses, want 2 hits 1 miss", stats.TxHits,
+			stats.TxMisses)
+	}
+	if stats.OutputHits != 2 || stats.OutputMisses != 3 {
+		t.Fatalf("Wrong output stats : got %d hits %d misses, want 2 hits 3 misses",
+			stats.OutputHits, stats.OutputMisses)
+	}
+}
+
+func Test_CachingNode_Limits(t *testing.T) {
+	ctx := context.Background()
+
+	node := inspectortest.NewNode()
+	var parents []*wire.MsgTx
+	for i := 0; i < 4; i++ {
+		parent := newParentTx(t)
+		node.AddTx(parent)
+		parents = append(parents, parent)
+	}
+
+	cache := inspector.NewCachingNode(node, 2, 0)
+	for _, parent := range parents {
+		cache.GetTx(ctx, *parent.TxHash())
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 2 {
+		t.Fatalf("Wrong entry limit stats : got %d entries %d evictions, want 2 and 2",
+			stats.Entries, stats.Evictions)
+	}
+
+	// Most recent are retained.
+	cache.GetTx(ctx, *parents[3].TxHash())
+	if node.Calls().GetTx != 4 {
+		t.Fatalf("Most recent tx should be cached")
+	}
+
+	// Parents are about the same size, so only one fits.
+	txSize := stats.Bytes / uint64(stats.Entries)
+	cache = inspector.NewCachingNode(node, 0, txSize*3/2)
+	for _, parent := range parents {
+		cache.GetTx(ctx, *parent.TxHash())
+	}
+
+	stats = cache.Stats()
+	if stats.Entries != 1 || stats.Bytes > txSize*3/2 {
+		t.Fatalf("Wrong byte limit stats : got %d entries %d bytes, want 1 entry <= %d bytes",
+			stats.Entries, stats.Bytes, txSize*3/2)
+	}
+}
+
+func Test_CachingNode_WrongOutputs(t *testing.T) {
+	ctx := context.Background()
+
+	node := inspectortest.NewNode()
+	var parents []*wire.MsgTx
+	for i := 0; i < 2; i++ {
+		parent := newParentTx(t)
+		node.AddTx(parent)
+		parents = append(parents, parent)
+	}
+
+	outpoints := []wire.OutPoint{
+		{Hash: *parents[0].TxHash(), Index: 0},
+		{Hash: *parents[1].TxHash(), Index: 0},
+	}
+
+	mutations := []func([]bitcoin.UTXO) []bitcoin.UTXO{
+		func(outputs []bitcoin.UTXO) []bitcoin.UTXO { // short
+			return outputs[:1]
+		},
+		func(outputs []bitcoin.UTXO) []bitcoin.UTXO { // out of order
+			return []bitcoin.UTXO{outputs[1], outputs[0]}
+		},
+	}
+
+	for i, mutate := range mutations {
+		cache := inspector.NewCachingNode(&mutatingNode{NodeInterface: node, mutate: mutate}, 0, 0)
+
+		if _, err := cache.GetOutputs(ctx, outpoints); err == nil {
+			t.Fatalf("GetOutputs should fail for wrong outputs %d", i)
+		}
+
+		if stats := cache.Stats(); stats.Entries != 0 {
+			t.Fatalf("Wrong outputs %d should not be cached : got %d entries", i,
+				stats.Entries)
+		}
+	}
+}
+
+// wrongTxNode returns another tx from GetTx.
+type wrongTxNode struct {
+	inspector.NodeInterface
+	tx *wire.MsgTx
+}
+
+func (n *wrongTxNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	return n.tx, nil
+}
+
+func Test_CachingNode_WrongTx(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+	cache := inspector.NewCachingNode(&wrongTxNode{NodeInterface: node, tx: newParentTx(t)}, 0, 0)
+
+	if _, err := cache.GetTx(ctx, *parent.TxHash()); err == nil {
+		t.Fatalf("GetTx should fail for wrong tx")
+	}
+
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Fatalf("Wrong tx should not be cached : got %d entries", stats.Entries)
+	}
+}
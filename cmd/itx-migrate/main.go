@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tokenized/inspector"
+
+	"github.com/pkg/errors"
+)
+
+/**
+ * itx-migrate
+ *
+ * Rewrites transactions serialized with inspector Transaction.Write in any previous version of the
+ * binary format in the current version.
+ *
+ * Directory mode: each regular file in the input directory is one serialized transaction. Files
+ * are rewritten in place, or to the output directory when specified. Files already in the current
+ * version are only copied when an output directory is specified.
+ *
+ * Stream mode: the input is serialized transactions concatenated together. They are written
+ * concatenated to the output file, or stdout. Since the records have no framing, the start of the
+ * record after one that fails to parse isn't known, so the run aborts with an error at the first
+ * record that fails. The records before it have already been written to the output.
+ */
+
+func main() {
+	input := flag.String("in", "-", "input directory, file, or - for stdin")
+	output := flag.String("out", "",
+		"output directory in directory mode, or file in stream mode (default in place / stdout)")
+	dryRun := flag.Bool("dry-run", false, "parse and report without writing anything")
+	isTest := flag.Bool("test", false, "decode actions with the test protocol ID")
+	maxSize := flag.Uint64("max-size", 0,
+		"maximum size in bytes of a record (default large enough for any network tx)")
+	flag.Parse()
+
+	m := &migrator{
+		opts:   inspector.DefaultParseOptions(*isTest),
+		dryRun: *dryRun,
+		log:    os.Stderr,
+	}
+	m.opts.ReadLimits.MaxSize = *maxSize
+
+	var err error
+	if stat, statErr := os.Stat(*input); *input != "-" && statErr == nil && stat.IsDir() {
+		err = m.migrateDirectory(*input, *output)
+	} else {
+		err = m.migrateStream(*input, *output)
+	}
+
+	m.printSummary()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed : %s\n", err)
+		os.Exit(1)
+	}
+
+	if m.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+type migrator struct {
+	opts   inspector.ParseOptions
+	dryRun bool
+	log    io.Writer
+
+	// versions is the count of records read by the version they were stored in.
+	versions map[uint8]int
+	migrated int
+	failed   int
+}
+
+// migrateDirectory migrates each file in the input directory.
+func (m *migrator) migrateDirectory(inputDir, outputDir string) error {
+	entries, err := os.ReadDir(inputDir)
+	if err != nil {
+		return errors.Wrap(err, "read directory")
+	}
+
+	if outputDir != "" && !m.dryRun {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return errors.Wrap(err, "create output directory")
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		path := filepath.Join(inputDir, entry.Name())
+		outputPath := path
+		if outputDir != "" {
+			outputPath = filepath.Join(outputDir, entry.Name())
+		}
+
+		if err := m.migrateFile(path, outputPath); err != nil {
+			m.failed++
+			fmt.Fprintf(m.log, "%s : %s\n", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *migrator) migrateFile(path, outputPath string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "read")
+	}
+
+	if len(b) == 0 {
+		return errors.New("Empty file")
+	}
+
+	itx, err := m.read(bytes.NewReader(b), b[0])
+	if err != nil {
+		return err
+	}
+
+	if b[0] == inspector.CurrentVersion && outputPath == path {
+		return nil // already current
+	}
+
+	if m.dryRun {
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write(buf); err != nil {
+		return errors.Wrap(err, "write")
+	}
+
+	// Write to a temporary file and rename so the original is never left partially written.
+	tempPath := outputPath + ".tmp"
+	if err := os.WriteFile(tempPath, buf.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, "write")
+	}
+
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		os.Remove(tempPath)
+		return errors.Wrap(err, "rename")
+	}
+
+	return nil
+}
+
+// migrateStream migrates concatenated records from the input to the output.
+func (m *migrator) migrateStream(input, output string) error {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return errors.Wrap(err, "open input")
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var w io.Writer = io.Discard
+	if !m.dryRun {
+		w = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return errors.Wrap(err, "create output")
+			}
+			defer file.Close()
+			w = file
+		}
+	}
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	for index := 0; ; index++ {
+		version, err := br.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "record %d", index)
+		}
+
+		itx, err := m.read(br, version[0])
+		if err != nil {
+			m.failed++
+			fmt.Fprintf(m.log, "record %d : %s\n", index, err)
+			return errors.Wrapf(err, "record %d", index)
+		}
+
+		if err := itx.Write(bw); err != nil {
+			return errors.Wrapf(err, "write record %d", index)
+		}
+	}
+}
+
+// read parses a record and counts its version.
+func (m *migrator) read(r io.Reader, version uint8) (*inspector.Transaction, error) {
+	itx := &inspector.Transaction{}
+	if err := itx.ReadWithOptions(r, m.opts); err != nil {
+		return nil, errors.Wrapf(err, "read version %d", version)
+	}
+
+	if m.versions == nil {
+		m.versions = make(map[uint8]int)
+	}
+	m.versions[version]++
+
+	if version != inspector.CurrentVersion {
+		m.migrated++
+	}
+
+	return itx, nil
+}
+
+func (m *migrator) printSummary() {
+	var versions []int
+	for version := range m.versions {
+		versions = append(versions, int(version))
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		fmt.Fprintf(m.log, "Version %d : %d\n", version, m.versions[uint8(version)])
+	}
+
+	action := "Migrated"
+	if m.dryRun {
+		action = "Would migrate"
+	}
+	fmt.Fprintf(m.log, "%s %d records to version %d, %d failed\n", action, m.migrated,
+		inspector.CurrentVersion, m.failed)
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+)
+
+func Test_MigrateStream(t *testing.T) {
+	dir := t.TempDir()
+	itx, parent := newTestTx(t)
+
+	input := &bytes.Buffer{}
+	for version := uint8(0); version < inspector.CurrentVersion; version++ {
+		input.Write(writeRecord(t, version, itx, parent))
+	}
+
+	inputPath := filepath.Join(dir, "input")
+	outputPath := filepath.Join(dir, "output")
+	if err := os.WriteFile(inputPath, input.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write input : %s", err)
+	}
+
+	m := newTestMigrator()
+	if err := m.migrateStream(inputPath, outputPath); err != nil {
+		t.Fatalf("Failed to migrate : %s", err)
+	}
+
+	if m.migrated != int(inspector.CurrentVersion) || m.failed != 0 {
+		t.Fatalf("Wrong counts : got %d migrated %d failed, want %d migrated 0 failed",
+			m.migrated, m.failed, inspector.CurrentVersion)
+	}
+
+	b, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output : %s", err)
+	}
+
+	r := bytes.NewReader(b)
+	for i := 0; i < int(inspector.CurrentVersion); i++ {
+		if b[len(b)-r.Len()] != inspector.CurrentVersion {
+			t.Fatalf("Record %d not current version : %d", i, b[len(b)-r.Len()])
+		}
+
+		checkTx(t, r, itx)
+	}
+
+	if r.Len() != 0 {
+		t.Fatalf("Extra output : %d bytes", r.Len())
+	}
+
+	// The run fails at a record that can't be read, after writing the records before it.
+	input.Write([]byte{inspector.CurrentVersion + 1})
+	if err := os.WriteFile(inputPath, input.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write input : %s", err)
+	}
+
+	m = newTestMigrator()
+	if err := m.migrateStream(inputPath, outputPath); err == nil {
+		t.Fatalf("Migrating invalid record should fail")
+	}
+
+	if m.migrated != int(inspector.CurrentVersion) || m.failed != 1 {
+		t.Fatalf("Wrong counts : got %d migrated %d failed, want %d migrated 1 failed",
+			m.migrated, m.failed, inspector.CurrentVersion)
+	}
+
+	written, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output : %s", err)
+	}
+
+	if !bytes.Equal(written, b) {
+		t.Fatalf("Records before the invalid record not written")
+	}
+}
+
+func Test_MigrateDirectory(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "output")
+	itx, parent := newTestTx(t)
+
+	for version := uint8(0); version <= inspector.CurrentVersion; version++ {
+		b := writeRecord(t, version, itx, parent)
+		path := filepath.Join(inputDir, string(rune('0'+version)))
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			t.Fatalf("Failed to write input : %s", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(inputDir, "invalid"), []byte{0xff},
+		0644); err != nil {
+		t.Fatalf("Failed to write input : %s", err)
+	}
+
+	m := newTestMigrator()
+	if err := m.migrateDirectory(inputDir, outputDir); err != nil {
+		t.Fatalf("Failed to migrate : %s", err)
+	}
+
+	if m.migrated != int(inspector.CurrentVersion) || m.failed != 1 {
+		t.Fatalf("Wrong counts : got %d migrated %d failed, want %d migrated 1 failed",
+			m.migrated, m.failed, inspector.CurrentVersion)
+	}
+
+	for version := uint8(0); version <= inspector.CurrentVersion; version++ {
+		b, err := os.ReadFile(filepath.Join(outputDir, string(rune('0'+version))))
+		if err != nil {
+			t.Fatalf("Failed to read output %d : %s", version, err)
+		}
+
+		if b[0] != inspector.CurrentVersion {
+			t.Fatalf("Output %d not current version : %d", version, b[0])
+		}
+
+		checkTx(t, bytes.NewReader(b), itx)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "invalid")); !os.IsNotExist(err) {
+		t.Fatalf("Invalid record should not be written : %v", err)
+	}
+}
+
+func Test_MigrateMaxSize(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := filepath.Join(t.TempDir(), "output")
+	itx, parent := newTestTx(t)
+
+	b := writeRecord(t, 0, itx, parent)
+	if err := os.WriteFile(filepath.Join(inputDir, "0"), b, 0644); err != nil {
+		t.Fatalf("Failed to write input : %s", err)
+	}
+
+	m := newTestMigrator()
+	m.opts.ReadLimits.MaxSize = uint64(len(b) - 1)
+	if err := m.migrateDirectory(inputDir, outputDir); err != nil {
+		t.Fatalf("Failed to migrate : %s", err)
+	}
+
+	if m.migrated != 0 || m.failed != 1 {
+		t.Fatalf("Wrong counts : got %d migrated %d failed, want 0 migrated 1 failed",
+			m.migrated, m.failed)
+	}
+
+	m = newTestMigrator()
+	m.opts.ReadLimits.MaxSize = uint64(len(b))
+	if err := m.migrateDirectory(inputDir, outputDir); err != nil {
+		t.Fatalf("Failed to migrate : %s", err)
+	}
+
+	if m.migrated != 1 || m.failed != 0 {
+		t.Fatalf("Wrong counts : got %d migrated %d failed, want 1 migrated 0 failed",
+			m.migrated, m.failed)
+	}
+}
+
+func newTestMigrator() *migrator {
+	return &migrator{
+		opts: inspector.DefaultParseOptions(true),
+		log:  io.Discard,
+	}
+}
+
+// newTestTx returns a promoted tx and the parent tx containing the output it spends.
+func newTestTx(t *testing.T) (*inspector.Transaction, *wire.MsgTx) {
+	ctx := context.Background()
+
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+	lockingScript, err := key.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	parent := wire.NewMsgTx(1)
+	parent.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 0xffffffff}, nil))
+	parent.AddTxOut(wire.NewTxOut(2000, lockingScript))
+	parent.AddTxOut(wire.NewTxOut(3000, lockingScript))
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	tx.AddTxOut(wire.NewTxOut(2500, lockingScript))
+
+	itx, err := inspector.NewTransactionFromOutputs(ctx, *tx.TxHash(), tx,
+		[]*wire.TxOut{parent.TxOut[1]}, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+	itx.RejectCode = 5
+
+	return itx, parent
+}
+
+// writeRecord serializes the tx in the binary format version.
+func writeRecord(t *testing.T, version uint8, itx *inspector.Transaction,
+	parent *wire.MsgTx) []byte {
+
+	buf := &bytes.Buffer{}
+	if version == inspector.CurrentVersion {
+		if err := itx.Write(buf); err != nil {
+			t.Fatalf("Failed to write tx : %s", err)
+		}
+		return buf.Bytes()
+	}
+
+	buf.WriteByte(version)
+	if err := itx.MsgTx.Serialize(buf); err != nil {
+		t.Fatalf("Failed to serialize tx : %s", err)
+	}
+
+	if version >= 2 {
+		binary.Write(buf, binary.LittleEndian, uint32(len(itx.Inputs)))
+	}
+
+	for i, input := range itx.Inputs {
+		var err error
+		switch version {
+		case 0:
+			err = parent.Serialize(buf)
+		case 1, 2:
+			err = bitcoin.UTXO{
+				Hash:          itx.MsgTx.TxIn[i].PreviousOutPoint.Hash,
+				Index:         itx.MsgTx.TxIn[i].PreviousOutPoint.Index,
+				Value:         input.Value,
+				LockingScript: input.LockingScript,
+			}.Write(buf)
+		default:
+			err = input.Write(buf)
+		}
+		if err != nil {
+			t.Fatalf("Failed to write version %d input %d : %s", version, i, err)
+		}
+	}
+
+	buf.WriteByte(uint8(itx.RejectCode))
+	return buf.Bytes()
+}
+
+// checkTx reads a record in the current version and verifies it matches the tx.
+func checkTx(t *testing.T, r io.Reader, want *inspector.Transaction) {
+	got := &inspector.Transaction{}
+	if err := got.ReadWithOptions(r, inspector.DefaultParseOptions(true)); err != nil {
+		t.Fatalf("Failed to read migrated tx : %s", err)
+	}
+
+	if !got.Hash.Equal(&want.Hash) {
+		t.Fatalf("Wrong hash : got %s, want %s", got.Hash, want.Hash)
+	}
+
+	if got.RejectCode != want.RejectCode {
+		t.Fatalf("Wrong reject code : got %d, want %d", got.RejectCode, want.RejectCode)
+	}
+
+	if len(got.Inputs) != len(want.Inputs) {
+		t.Fatalf("Wrong input count : got %d, want %d", len(got.Inputs), len(want.Inputs))
+	}
+
+	for i, input := range got.Inputs {
+		if input.Value != want.Inputs[i].Value ||
+			!input.LockingScript.Equal(want.Inputs[i].LockingScript) {
+			t.Fatalf("Wrong input %d : got %d %s, want %d %s", i, input.Value,
+				input.LockingScript, want.Inputs[i].Value, want.Inputs[i].LockingScript)
+		}
+	}
+}
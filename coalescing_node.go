@@ -0,0 +1,177 @@
+package inspector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+)
+
+// CoalescingNode wraps a NodeInterface and combines concurrent GetOutputs calls. Outpoints
+// requested within a window, or until a batch size is reached, are de-duplicated and requested
+// from the wrapped node in one call. GetTx and SaveTx are passed through.
+type CoalescingNode struct {
+	node      NodeInterface
+	window    time.Duration
+	batchSize int
+
+	current *coalescingBatch
+
+	lock sync.Mutex
+}
+
+type coalescingBatch struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	requests  []*coalescingRequest
+	waiting   int // requests still waiting for the results
+	outpoints []wire.OutPoint
+	added     map[wire.OutPoint]bool
+	timer     *time.Timer
+}
+
+type coalescingRequest struct {
+	outpoints []wire.OutPoint
+	outputs   []bitcoin.UTXO
+	err       error
+	done      chan struct{}
+}
+
+// NewCoalescingNode creates a CoalescingNode. A batch is sent when the window has passed since its
+// first request, or when it contains batchSize unique outpoints. A zero batchSize means batches
+// are only limited by the window.
+func NewCoalescingNode(node NodeInterface, window time.Duration,
+	batchSize int) *CoalescingNode {
+
+	return &CoalescingNode{
+		node:      node,
+		window:    window,
+		batchSize: batchSize,
+	}
+}
+
+func (n *CoalescingNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	return n.node.SaveTx(ctx, tx)
+}
+
+func (n *CoalescingNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	return n.node.GetTx(ctx, hash)
+}
+
+// GetOutputs adds the outpoints to the current batch and waits for the batch results.
+func (n *CoalescingNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	if len(outpoints) == 0 {
+		return nil, nil
+	}
+
+	request := &coalescingRequest{
+		outpoints: outpoints,
+		done:      make(chan struct{}),
+	}
+
+	n.lock.Lock()
+	batch := n.current
+	if batch == nil {
+		// The call for the batch is shared by callers, so it isn't canceled by any one of them.
+		// It is canceled when all of them have stopped waiting.
+		batchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		batch = &coalescingBatch{
+			ctx:    batchCtx,
+			cancel: cancel,
+			added:  make(map[wire.OutPoint]bool),
+		}
+		batch.timer = time.AfterFunc(n.window, func() { n.flushIfCurrent(batch) })
+		n.current = batch
+	}
+
+	batch.requests = append(batch.requests, request)
+	batch.waiting++
+	for _, outpoint := range outpoints {
+		if !batch.added[outpoint] {
+			batch.added[outpoint] = true
+			batch.outpoints = append(batch.outpoints, outpoint)
+		}
+	}
+
+	full := n.batchSize > 0 && len(batch.outpoints) >= n.batchSize
+	if full {
+		n.current = nil
+		batch.timer.Stop()
+	}
+	n.lock.Unlock()
+
+	if full {
+		go n.flush(batch)
+	}
+
+	select {
+	case <-request.done:
+		return request.outputs, request.err
+	case <-ctx.Done():
+		n.abandon(batch)
+		return nil, ctx.Err()
+	}
+}
+
+// abandon is called when a request stops waiting for the batch results. When no requests are
+// waiting the batch is canceled, and isn't sent if it hasn't been already.
+func (n *CoalescingNode) abandon(batch *coalescingBatch) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	batch.waiting--
+	if batch.waiting > 0 {
+		return
+	}
+
+	if n.current == batch {
+		n.current = nil
+		batch.timer.Stop()
+	}
+	batch.cancel()
+}
+
+func (n *CoalescingNode) flushIfCurrent(batch *coalescingBatch) {
+	n.lock.Lock()
+	if n.current != batch {
+		n.lock.Unlock()
+		return // already flushed because it was full
+	}
+	n.current = nil
+	n.lock.Unlock()
+
+	n.flush(batch)
+}
+
+// flush requests the batch outpoints and fans the results out to the requests. When an outpoint
+// isn't found only the requests containing it fail, and the remaining outpoints are requested
+// again.
+func (n *CoalescingNode) flush(batch *coalescingBatch) {
+	defer batch.cancel()
+
+	requesters := make(map[wire.OutPoint][]int, len(batch.outpoints))
+	for i, request := range batch.requests {
+		for _, outpoint := range request.outpoints {
+			requesters[outpoint] = append(requesters[outpoint], i)
+		}
+	}
+
+	errs := make([]error, len(batch.requests))
+	outputs := getRequestedOutputs(batch.ctx, n.node, batch.outpoints, requesters, errs)
+
+	for i, request := range batch.requests {
+		if errs[i] != nil {
+			request.err = errs[i]
+		} else {
+			request.outputs = make([]bitcoin.UTXO, len(request.outpoints))
+			for j, outpoint := range request.outpoints {
+				request.outputs[j] = outputs[outpoint]
+			}
+		}
+		close(request.done)
+	}
+}
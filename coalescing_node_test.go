@@ -0,0 +1,188 @@
+package inspector_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_CoalescingNode(t *testing.T) {
+	ctx := context.Background()
+
+	node := inspectortest.NewNode()
+	var outpoints []wire.OutPoint
+	for i := 0; i < 4; i++ {
+		parent := newParentTx(t)
+		node.AddTx(parent)
+		outpoints = append(outpoints, wire.OutPoint{Hash: *parent.TxHash(), Index: 0})
+	}
+
+	coalescing := inspector.NewCoalescingNode(node, 20*time.Millisecond, 0)
+
+	// Overlapping requests in different orders.
+	requests := [][]wire.OutPoint{
+		{outpoints[0], outpoints[1]},
+		{outpoints[1], outpoints[0]},
+		{outpoints[3], outpoints[2], outpoints[1]},
+		{outpoints[2]},
+	}
+
+	var wait sync.WaitGroup
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		wait.Add(1)
+		go func(i int, request []wire.OutPoint) {
+			defer wait.Done()
+
+			outputs, err := coalescing.GetOutputs(ctx, request)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			for j, output := range outputs {
+				if !output.Hash.Equal(&request[j].Hash) || output.Index != request[j].Index {
+					errs[i] = errors.Errorf("Wrong output %d : got %s:%d, want %s", j,
+						output.Hash, output.Index, request[j])
+					return
+				}
+			}
+		}(i, request)
+	}
+	wait.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Request %d failed : %s", i, err)
+		}
+	}
+
+	if calls := node.Calls(); calls.GetOutputs != 1 || calls.OutPoints != 4 {
+		t.Fatalf("Wrong underlying calls : got %d calls %d outpoints, want 1 call 4 outpoints",
+			calls.GetOutputs, calls.OutPoints)
+	}
+}
+
+func Test_CoalescingNode_BatchSize(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+
+	// Window is long enough that the test times out unless the batch size sends the batch.
+	coalescing := inspector.NewCoalescingNode(node, time.Hour, 2)
+
+	var wait sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			_, errs[i] = coalescing.GetOutputs(ctx,
+				[]wire.OutPoint{{Hash: *parent.TxHash(), Index: uint32(i)}})
+		}(i)
+	}
+	wait.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Request %d failed : %s", i, err)
+		}
+	}
+
+	if calls := node.Calls(); calls.GetOutputs != 1 {
+		t.Fatalf("Wrong underlying calls : got %d, want %d", calls.GetOutputs, 1)
+	}
+}
+
+func Test_CoalescingNode_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+
+	found := wire.OutPoint{Hash: *parent.TxHash(), Index: 0}
+	missing := wire.OutPoint{Hash: bitcoin.Hash32{1}, Index: 0}
+
+	coalescing := inspector.NewCoalescingNode(node, 20*time.Millisecond, 0)
+
+	var wait sync.WaitGroup
+	var foundErr, missingErr error
+	wait.Add(2)
+	go func() {
+		defer wait.Done()
+		_, foundErr = coalescing.GetOutputs(ctx, []wire.OutPoint{found})
+	}()
+	go func() {
+		defer wait.Done()
+		_, missingErr = coalescing.GetOutputs(ctx, []wire.OutPoint{found, missing})
+	}()
+	wait.Wait()
+
+	if foundErr != nil {
+		t.Fatalf("Request without missing outpoint failed : %s", foundErr)
+	}
+
+	notFound, ok := errors.Cause(missingErr).(*inspector.OutputNotFoundError)
+	if !ok || notFound.OutPoint != missing {
+		t.Fatalf("Wrong missing output error : %v", missingErr)
+	}
+}
+
+// blockingNode blocks GetOutputs until the context is done and reports the context error.
+type blockingNode struct {
+	inspector.NodeInterface
+	canceled chan error
+}
+
+func (n *blockingNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	<-ctx.Done()
+	n.canceled <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func Test_CoalescingNode_Abandoned(t *testing.T) {
+	ctx := context.Background()
+
+	node := &blockingNode{NodeInterface: inspectortest.NewNode(), canceled: make(chan error, 1)}
+	coalescing := inspector.NewCoalescingNode(node, time.Millisecond, 0)
+
+	outpoints := []wire.OutPoint{{Hash: bitcoin.Hash32{1}, Index: 0}}
+
+	var wait sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wait.Add(1)
+		go func(timeout time.Duration) {
+			defer wait.Done()
+
+			requestCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			if _, err := coalescing.GetOutputs(requestCtx,
+				outpoints); err != context.DeadlineExceeded {
+				t.Errorf("Wrong error : got %v, want %v", err, context.DeadlineExceeded)
+			}
+		}(time.Duration(i+1) * 20 * time.Millisecond)
+	}
+	wait.Wait()
+
+	// The underlying call is canceled once no callers are waiting for it.
+	select {
+	case err := <-node.canceled:
+		if err != context.Canceled {
+			t.Fatalf("Wrong underlying error : got %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Underlying call not canceled")
+	}
+}
@@ -0,0 +1,122 @@
+package inspector
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tokenized/envelope/pkg/golang/envelope"
+	envelopeBase "github.com/tokenized/envelope/pkg/golang/envelope/base"
+	envelopeV1 "github.com/tokenized/envelope/pkg/golang/envelope/v1"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+)
+
+// DecodeDiagnostic describes an output that contained a Tokenized envelope that failed to decode.
+type DecodeDiagnostic struct {
+	OutputIndex int
+	ProtocolIDs envelopeBase.ProtocolIDs
+	Err         error
+}
+
+func (d DecodeDiagnostic) String() string {
+	return fmt.Sprintf("output %d (%s): %s", d.OutputIndex, d.ProtocolIDs, d.Err)
+}
+
+// DecodeDiagnostics returns the outputs that looked like Tokenized envelopes, but failed to decode.
+func (itx *Transaction) DecodeDiagnostics() []DecodeDiagnostic {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	var result []DecodeDiagnostic
+	for i, output := range itx.Outputs {
+		if output.DecodeError == nil {
+			continue
+		}
+
+		result = append(result, DecodeDiagnostic{
+			OutputIndex: i,
+			ProtocolIDs: output.ProtocolIDs,
+			Err:         output.DecodeError,
+		})
+	}
+
+	return result
+}
+
+// scriptData is the data decoded from a locking script.
+type scriptData struct {
+	action      actions.Action
+	protocolIDs envelopeBase.ProtocolIDs
+	payloads    []*Payload
+	decodeErr   error
+}
+
+// decodeScript applies the decoders specified by the options to the script. The error returned is
+// the Tokenized decode error, which is only set for envelopes with an accepted protocol ID, so
+// Strict only applies to those. Errors from other decoders are only contained in their payloads.
+func decodeScript(script bitcoin.Script, opts ParseOptions) (scriptData, error) {
+	result := scriptData{
+		protocolIDs: envelopeProtocolIDs(script),
+	}
+
+	result.payloads, result.action, result.decodeErr = decodePayloads(script, opts)
+
+	if result.decodeErr != nil {
+		return result, errors.Wrap(result.decodeErr, TokenizedDecoderName)
+	}
+
+	return result, nil
+}
+
+// decodeAction returns the action contained in the script. It returns a nil action and nil error if
+// the script doesn't contain an action, and an error if the script is an envelope containing an
+// accepted Tokenized protocol ID, but failed to decode.
+func decodeAction(script bitcoin.Script, opts ParseOptions) (actions.Action, error) {
+	var decodeErr error
+	for _, isTest := range opts.testFlags() {
+		for _, decoder := range opts.actionDecoders() {
+			action, err := decoder(script, isTest)
+			if err == nil {
+				return action, nil
+			}
+
+			if errors.Cause(err) != protocol.ErrNotTokenized && decodeErr == nil {
+				decodeErr = err
+			}
+		}
+	}
+
+	if decodeErr != nil && !opts.containsProtocolID(envelopeProtocolIDs(script)) {
+		// Errors from scripts that aren't Tokenized envelopes are just other data.
+		return nil, nil
+	}
+
+	return nil, decodeErr
+}
+
+// envelopeProtocolIDs returns the payload protocol IDs of the script if it is an envelope.
+func envelopeProtocolIDs(script bitcoin.Script) envelopeBase.ProtocolIDs {
+	version, err := envelopeBase.ParseHeader(bytes.NewReader(script))
+	if err != nil {
+		return nil
+	}
+
+	if version == 0 {
+		message, err := envelope.Deserialize(bytes.NewReader(script))
+		if err != nil {
+			return nil
+		}
+
+		return message.PayloadProtocols()
+	}
+
+	protocolIDs, err := envelopeV1.ParseProtocolIDs(bytes.NewReader(script))
+	if err != nil {
+		return nil
+	}
+
+	return protocolIDs
+}
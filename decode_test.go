@@ -0,0 +1,60 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func Test_DecodeDiagnostics(t *testing.T) {
+	ctx := context.Background()
+
+	validScript, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	tx := newTestTx(t, newMalformedActionScript(t, true))
+	tx.AddTxOut(wire.NewTxOut(0, validScript))
+	tx.AddTxOut(wire.NewTxOut(0, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 0x01, 0x01}))
+
+	// Not Tokenized with the main protocol ID so not a decode failure.
+	tx.AddTxOut(wire.NewTxOut(0, newMalformedActionScript(t, false)))
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if itx.Outputs[2].Action == nil {
+		t.Fatalf("Valid action should be decoded")
+	}
+	if len(itx.Outputs[2].ProtocolIDs) != 1 ||
+		string(itx.Outputs[2].ProtocolIDs[0]) != protocol.TestProtocolID {
+		t.Fatalf("Wrong protocol IDs : got %s, want %s", itx.Outputs[2].ProtocolIDs,
+			protocol.TestProtocolID)
+	}
+
+	if itx.Outputs[3].ProtocolIDs != nil {
+		t.Fatalf("Non-envelope should not have protocol IDs : %s", itx.Outputs[3].ProtocolIDs)
+	}
+
+	diagnostics := itx.DecodeDiagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Wrong diagnostic count : got %d, want %d", len(diagnostics), 1)
+	}
+
+	t.Logf("Diagnostic : %s", diagnostics[0])
+
+	if diagnostics[0].OutputIndex != 1 {
+		t.Fatalf("Wrong diagnostic output index : got %d, want %d", diagnostics[0].OutputIndex, 1)
+	}
+
+	if diagnostics[0].Err == nil {
+		t.Fatalf("Diagnostic missing error")
+	}
+}
@@ -0,0 +1,232 @@
+package inspector
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/actions"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenizedDecoderName is the name of the built-in decoder for Tokenized actions.
+	TokenizedDecoderName = "tokenized"
+
+	// Bitcom protocol prefixes.
+	BitcomPrefixB   = "19HxigV4QyBv3tHpQVcUEQyq1pzZVdoAut"
+	BitcomPrefixMAP = "1PuQa7K62MiKCtssSLKy1kh56WWU7MtUR5"
+	BitcomPrefixAIP = "15PciHG22SNLQJXMoSUaWVi7WSqc7hCfva"
+
+	// bitcomSeparator is the pipe character used to separate protocols in a bitcom script.
+	bitcomSeparator = "|"
+)
+
+var (
+	decoders     = []Decoder{&TokenizedDecoder{}}
+	decodersLock sync.RWMutex
+)
+
+// Decoder decodes protocol data from locking scripts.
+type Decoder interface {
+	// Name returns a unique name for the decoder. It is used as the protocol of the payloads it
+	// decodes.
+	Name() string
+
+	// Decode returns the data contained in the script. It returns nil data and nil error when the
+	// script doesn't contain data for the decoder's protocol, and an error when the script contains
+	// data for the protocol that is invalid.
+	Decode(script bitcoin.Script, opts ParseOptions) (interface{}, error)
+}
+
+// Payload is protocol data decoded from a locking script.
+type Payload struct {
+	Protocol string      `json:"protocol"`
+	Data     interface{} `json:"data,omitempty"`
+
+	// Err is the reason the script contained data for the protocol that failed to decode.
+	Err error `json:"-"`
+}
+
+// RegisterDecoder adds a decoder that is applied to locking scripts when ParseOptions.Decoders is
+// empty. It replaces any registered decoder with the same name.
+func RegisterDecoder(decoder Decoder) {
+	decodersLock.Lock()
+	defer decodersLock.Unlock()
+
+	name := decoder.Name()
+	for i, d := range decoders {
+		if d.Name() == name {
+			decoders[i] = decoder
+			return
+		}
+	}
+
+	decoders = append(decoders, decoder)
+}
+
+// UnregisterDecoder removes the registered decoder with the specified name.
+func UnregisterDecoder(name string) {
+	decodersLock.Lock()
+	defer decodersLock.Unlock()
+
+	for i, d := range decoders {
+		if d.Name() == name {
+			decoders = append(decoders[:i:i], decoders[i+1:]...)
+			return
+		}
+	}
+}
+
+// RegisteredDecoders returns the registered decoders in the order they are applied.
+func RegisteredDecoders() []Decoder {
+	decodersLock.RLock()
+	defer decodersLock.RUnlock()
+
+	result := make([]Decoder, len(decoders))
+	copy(result, decoders)
+	return result
+}
+
+// TokenizedDecoder decodes Tokenized actions using the protocol IDs and action decoders in the
+// parse options. It is registered by default.
+type TokenizedDecoder struct{}
+
+func (d *TokenizedDecoder) Name() string {
+	return TokenizedDecoderName
+}
+
+// Decode returns the actions.Action contained in the script.
+func (d *TokenizedDecoder) Decode(script bitcoin.Script,
+	opts ParseOptions) (interface{}, error) {
+
+	action, err := decodeAction(script, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if action == nil {
+		return nil, nil // return untyped nil so the payload isn't added
+	}
+
+	return action, nil
+}
+
+// BitcomDecoder decodes the data pushed after a Bitcom protocol prefix in an OP_RETURN script, for
+// example B://, MAP, or AIP. Multiple protocols in one script are separated by a "|" push.
+type BitcomDecoder struct {
+	name   string
+	prefix []byte
+}
+
+// BitcomPayload is the data from one protocol section of a Bitcom script, not including the prefix.
+type BitcomPayload struct {
+	Prefix string        `json:"prefix"`
+	Data   []bitcoin.Hex `json:"data"`
+}
+
+// NewBitcomDecoder creates a decoder for the Bitcom protocol with the specified prefix.
+func NewBitcomDecoder(name, prefix string) *BitcomDecoder {
+	return &BitcomDecoder{
+		name:   name,
+		prefix: []byte(prefix),
+	}
+}
+
+func (d *BitcomDecoder) Name() string {
+	return d.name
+}
+
+// Decode returns a *BitcomPayload for the first section of the script with the decoder's prefix.
+func (d *BitcomDecoder) Decode(script bitcoin.Script, opts ParseOptions) (interface{}, error) {
+	buf := bytes.NewReader(script)
+
+	// OP_RETURN or OP_FALSE OP_RETURN
+	opCode, err := buf.ReadByte()
+	if err != nil {
+		return nil, nil
+	}
+	if opCode == bitcoin.OP_FALSE {
+		if opCode, err = buf.ReadByte(); err != nil {
+			return nil, nil
+		}
+	}
+	if opCode != bitcoin.OP_RETURN {
+		return nil, nil
+	}
+
+	var result *BitcomPayload
+	sectionStart := true
+	for buf.Len() > 0 {
+		item, err := bitcoin.ParseScript(buf)
+		if err != nil {
+			if result != nil {
+				return nil, errors.Wrap(err, "parse script")
+			}
+			return nil, nil
+		}
+
+		if item.Type != bitcoin.ScriptItemTypePushData {
+			if result != nil {
+				return nil, errors.Wrapf(bitcoin.ErrInvalidScript, "non push op code: %s",
+					bitcoin.OpCodeToString(item.OpCode))
+			}
+			sectionStart = false
+			continue
+		}
+
+		if string(item.Data) == bitcomSeparator {
+			if result != nil {
+				return result, nil
+			}
+			sectionStart = true
+			continue
+		}
+
+		if result != nil {
+			result.Data = append(result.Data, bitcoin.Hex(item.Data))
+			continue
+		}
+
+		if sectionStart && bytes.Equal(item.Data, d.prefix) {
+			result = &BitcomPayload{Prefix: string(d.prefix)}
+		}
+		sectionStart = false
+	}
+
+	if result == nil {
+		return nil, nil // return untyped nil so the payload isn't added
+	}
+
+	return result, nil
+}
+
+// decodePayloads applies the decoders specified by the options to the script.
+func decodePayloads(script bitcoin.Script, opts ParseOptions) ([]*Payload, actions.Action, error) {
+	var payloads []*Payload
+	var action actions.Action
+	var actionErr error
+	for _, decoder := range opts.decoders() {
+		data, err := decoder.Decode(script, opts)
+		if err == nil && data == nil {
+			continue
+		}
+
+		payloads = append(payloads, &Payload{
+			Protocol: decoder.Name(),
+			Data:     data,
+			Err:      err,
+		})
+
+		if _, ok := decoder.(*TokenizedDecoder); ok {
+			if err != nil {
+				actionErr = err
+			} else if a, ok := data.(actions.Action); ok && action == nil {
+				action = a
+			}
+		}
+	}
+
+	return payloads, action, actionErr
+}
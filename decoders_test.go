@@ -0,0 +1,146 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func Test_Decoders_Bitcom(t *testing.T) {
+	ctx := context.Background()
+
+	script := bitcoin.ConcatScript(bitcoin.OP_FALSE, bitcoin.OP_RETURN,
+		bitcoin.PushData([]byte(BitcomPrefixB)), bitcoin.PushData([]byte("Hello")),
+		bitcoin.PushData([]byte("text/plain")),
+		bitcoin.PushData([]byte(bitcomSeparator)),
+		bitcoin.PushData([]byte(BitcomPrefixMAP)), bitcoin.PushData([]byte("SET")),
+		bitcoin.PushData([]byte("app")), bitcoin.PushData([]byte("test")))
+
+	actionScript, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	tx := newTestTx(t, script)
+	tx.AddTxOut(wire.NewTxOut(0, actionScript))
+
+	opts := DefaultParseOptions(true)
+	opts.Decoders = []Decoder{
+		&TokenizedDecoder{},
+		NewBitcomDecoder("B", BitcomPrefixB),
+		NewBitcomDecoder("MAP", BitcomPrefixMAP),
+		NewBitcomDecoder("AIP", BitcomPrefixAIP),
+	}
+
+	itx, err := NewTransactionFromWireWithOptions(ctx, tx, opts)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if len(itx.Outputs[0].Payloads) != 0 {
+		t.Fatalf("Payment output should not have payloads : %d", len(itx.Outputs[0].Payloads))
+	}
+
+	payloads := itx.Outputs[1].Payloads
+	if len(payloads) != 2 {
+		t.Fatalf("Wrong payload count : got %d, want %d", len(payloads), 2)
+	}
+
+	if payloads[0].Protocol != "B" {
+		t.Fatalf("Wrong first protocol : got %s, want %s", payloads[0].Protocol, "B")
+	}
+	b, ok := payloads[0].Data.(*BitcomPayload)
+	if !ok {
+		t.Fatalf("B payload is wrong type")
+	}
+	if len(b.Data) != 2 || string(b.Data[0]) != "Hello" || string(b.Data[1]) != "text/plain" {
+		t.Fatalf("Wrong B data : %v", b.Data)
+	}
+
+	if payloads[1].Protocol != "MAP" {
+		t.Fatalf("Wrong second protocol : got %s, want %s", payloads[1].Protocol, "MAP")
+	}
+	m, ok := payloads[1].Data.(*BitcomPayload)
+	if !ok {
+		t.Fatalf("MAP payload is wrong type")
+	}
+	if len(m.Data) != 3 || string(m.Data[0]) != "SET" {
+		t.Fatalf("Wrong MAP data : %v", m.Data)
+	}
+
+	payloads = itx.Outputs[2].Payloads
+	if len(payloads) != 1 || payloads[0].Protocol != TokenizedDecoderName {
+		t.Fatalf("Action output should have one tokenized payload")
+	}
+	if payloads[0].Data != itx.Outputs[2].Action {
+		t.Fatalf("Tokenized payload should be the action")
+	}
+}
+
+func Test_Decoders_Registry(t *testing.T) {
+	ctx := context.Background()
+
+	script := bitcoin.ConcatScript(bitcoin.OP_FALSE, bitcoin.OP_RETURN,
+		bitcoin.PushData([]byte(BitcomPrefixAIP)), bitcoin.PushData([]byte("BITCOIN_ECDSA")))
+	tx := newTestTx(t, script)
+
+	RegisterDecoder(NewBitcomDecoder("AIP", BitcomPrefixAIP))
+	defer UnregisterDecoder("AIP")
+
+	registered := RegisteredDecoders()
+	if len(registered) != 2 || registered[0].Name() != TokenizedDecoderName {
+		t.Fatalf("Tokenized decoder should be registered first")
+	}
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if len(itx.Outputs[1].Payloads) != 1 || itx.Outputs[1].Payloads[0].Protocol != "AIP" {
+		t.Fatalf("Registered decoder payload missing")
+	}
+
+	UnregisterDecoder("AIP")
+	if len(RegisteredDecoders()) != 1 {
+		t.Fatalf("Decoder not unregistered")
+	}
+}
+
+func Test_Decoders_Strict(t *testing.T) {
+	ctx := context.Background()
+
+	// B section containing a non push op code.
+	script := bitcoin.ConcatScript(bitcoin.OP_FALSE, bitcoin.OP_RETURN,
+		bitcoin.PushData([]byte(BitcomPrefixB)), bitcoin.PushData([]byte("Hello")),
+		bitcoin.Script{bitcoin.OP_DUP})
+	tx := newTestTx(t, script)
+
+	opts := DefaultParseOptions(true)
+	opts.Strict = true
+	opts.Decoders = []Decoder{
+		&TokenizedDecoder{},
+		NewBitcomDecoder("B", BitcomPrefixB),
+	}
+
+	// Malformed data for other protocols doesn't fail strict parsing.
+	itx, err := NewTransactionFromWireWithOptions(ctx, tx, opts)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	payloads := itx.Outputs[1].Payloads
+	if len(payloads) != 1 || payloads[0].Protocol != "B" || payloads[0].Err == nil {
+		t.Fatalf("B payload should contain the decode error")
+	}
+	t.Logf("B payload error : %s", payloads[0].Err)
+
+	if itx.Outputs[1].DecodeError != nil {
+		t.Fatalf("B payload error should not be a Tokenized decode error : %s",
+			itx.Outputs[1].DecodeError)
+	}
+}
@@ -0,0 +1,275 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotExtendedFormat means the data doesn't contain the Extended Format marker after the tx
+	// version.
+	ErrNotExtendedFormat = errors.New("Not extended format")
+
+	// extendedFormatMarker follows the tx version in Extended Format as defined by BIP-239. A
+	// normal tx can't start with it since it would have zero inputs.
+	extendedFormatMarker = []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0xef}
+)
+
+// NewTransactionFromExtendedFormat builds a promoted ITX from a hex Extended Format (BIP-239) tx,
+// where each input contains the value and locking script of the output it spends.
+func NewTransactionFromExtendedFormat(ctx context.Context, raw string,
+	isTest bool) (*Transaction, error) {
+
+	return NewTransactionFromExtendedFormatWithOptions(ctx, raw, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromExtendedFormatWithOptions builds a promoted ITX from a hex Extended Format
+// tx.
+func NewTransactionFromExtendedFormatWithOptions(ctx context.Context, raw string,
+	opts ParseOptions) (*Transaction, error) {
+
+	b, err := hex.DecodeString(strings.Trim(raw, "\n "))
+	if err != nil {
+		return nil, errors.Wrap(ErrDecodeFail, "decoding string")
+	}
+
+	return NewTransactionFromExtendedFormatBytesWithOptions(ctx, b, opts)
+}
+
+// NewTransactionFromExtendedFormatBytes builds a promoted ITX from a binary Extended Format tx.
+func NewTransactionFromExtendedFormatBytes(ctx context.Context, b []byte,
+	isTest bool) (*Transaction, error) {
+
+	return NewTransactionFromExtendedFormatBytesWithOptions(ctx, b, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromExtendedFormatBytesWithOptions builds a promoted ITX from a binary Extended
+// Format tx.
+func NewTransactionFromExtendedFormatBytesWithOptions(ctx context.Context, b []byte,
+	opts ParseOptions) (*Transaction, error) {
+
+	limits := opts.ReadLimits.orDefault()
+	if uint64(len(b)) > limits.MaxSize {
+		return nil, errors.Wrapf(ErrSizeLimit, "%d > %d", len(b), limits.MaxSize)
+	}
+
+	r := bytes.NewReader(b)
+	tx, outputs, err := readExtendedFormat(r, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("Extra extended format data : %d bytes", r.Len())
+	}
+
+	result, err := NewTransactionFromOutputsWithOptions(ctx, *tx.TxHash(), tx, outputs, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "promote")
+	}
+
+	return result, nil
+}
+
+// WriteExtendedFormat writes the tx in Extended Format (BIP-239). The tx must be promoted.
+func (itx *Transaction) WriteExtendedFormat(w io.Writer) error {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	if len(itx.Inputs) != len(itx.MsgTx.TxIn) {
+		return ErrUnpromotedTx
+	}
+
+	if unknown := itx.unknownInputs(); len(unknown) > 0 {
+		return errors.Wrapf(ErrIncompleteTx, "input %d unknown", unknown[0])
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, itx.MsgTx.Version); err != nil {
+		return errors.Wrap(err, "version")
+	}
+
+	if _, err := w.Write(extendedFormatMarker); err != nil {
+		return errors.Wrap(err, "marker")
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(itx.MsgTx.TxIn))); err != nil {
+		return errors.Wrap(err, "input count")
+	}
+
+	for i, txin := range itx.MsgTx.TxIn {
+		if err := writeExtendedInput(w, txin, itx.Inputs[i]); err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(itx.MsgTx.TxOut))); err != nil {
+		return errors.Wrap(err, "output count")
+	}
+
+	for i, txout := range itx.MsgTx.TxOut {
+		if err := binary.Write(w, binary.LittleEndian, txout.Value); err != nil {
+			return errors.Wrapf(err, "output %d value", i)
+		}
+
+		if err := writeScript(w, txout.LockingScript); err != nil {
+			return errors.Wrapf(err, "output %d script", i)
+		}
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, itx.MsgTx.LockTime); err != nil {
+		return errors.Wrap(err, "lock time")
+	}
+
+	return nil
+}
+
+func writeExtendedInput(w io.Writer, txin *wire.TxIn, input *Input) error {
+	if _, err := w.Write(txin.PreviousOutPoint.Hash[:]); err != nil {
+		return errors.Wrap(err, "outpoint hash")
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, txin.PreviousOutPoint.Index); err != nil {
+		return errors.Wrap(err, "outpoint index")
+	}
+
+	if err := writeScript(w, txin.UnlockingScript); err != nil {
+		return errors.Wrap(err, "unlocking script")
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, txin.Sequence); err != nil {
+		return errors.Wrap(err, "sequence")
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, input.Value); err != nil {
+		return errors.Wrap(err, "value")
+	}
+
+	if err := writeScript(w, input.LockingScript); err != nil {
+		return errors.Wrap(err, "locking script")
+	}
+
+	return nil
+}
+
+func writeScript(w io.Writer, script bitcoin.Script) error {
+	if err := wire.WriteVarInt(w, 0, uint64(len(script))); err != nil {
+		return errors.Wrap(err, "length")
+	}
+
+	if _, err := w.Write(script); err != nil {
+		return errors.Wrap(err, "script")
+	}
+
+	return nil
+}
+
+// readExtendedFormat reads an Extended Format tx and returns the tx and the outputs spent by its
+// inputs.
+func readExtendedFormat(r io.Reader, limits ReadLimits) (*wire.MsgTx, []*wire.TxOut, error) {
+	tx := &wire.MsgTx{}
+	if err := binary.Read(r, binary.LittleEndian, &tx.Version); err != nil {
+		return nil, nil, errors.Wrap(err, "version")
+	}
+
+	marker := make([]byte, len(extendedFormatMarker))
+	if _, err := io.ReadFull(r, marker); err != nil {
+		return nil, nil, errors.Wrap(err, "marker")
+	}
+	if !bytes.Equal(marker, extendedFormatMarker) {
+		return nil, nil, errors.Wrapf(ErrNotExtendedFormat, "marker %x", marker)
+	}
+
+	inputCount, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "input count")
+	}
+	if inputCount > uint64(limits.MaxInputCount) {
+		return nil, nil, errors.Wrapf(ErrInputCountLimit, "%d > %d", inputCount,
+			limits.MaxInputCount)
+	}
+
+	// Counts aren't used to allocate since they haven't been verified by reading the data.
+	var outputs []*wire.TxOut
+	for i := uint64(0); i < inputCount; i++ {
+		txin := &wire.TxIn{}
+		if _, err := io.ReadFull(r, txin.PreviousOutPoint.Hash[:]); err != nil {
+			return nil, nil, errors.Wrapf(err, "input %d outpoint hash", i)
+		}
+
+		if err := binary.Read(r, binary.LittleEndian,
+			&txin.PreviousOutPoint.Index); err != nil {
+			return nil, nil, errors.Wrapf(err, "input %d outpoint index", i)
+		}
+
+		txin.UnlockingScript, err = readVarScript(r, limits)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "input %d unlocking script", i)
+		}
+
+		if err := binary.Read(r, binary.LittleEndian, &txin.Sequence); err != nil {
+			return nil, nil, errors.Wrapf(err, "input %d sequence", i)
+		}
+
+		output, err := readTxOut(r, limits)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "input %d output", i)
+		}
+
+		tx.TxIn = append(tx.TxIn, txin)
+		outputs = append(outputs, output)
+	}
+
+	outputCount, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "output count")
+	}
+
+	for i := uint64(0); i < outputCount; i++ {
+		output, err := readTxOut(r, limits)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "output %d", i)
+		}
+
+		tx.TxOut = append(tx.TxOut, output)
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &tx.LockTime); err != nil {
+		return nil, nil, errors.Wrap(err, "lock time")
+	}
+
+	return tx, outputs, nil
+}
+
+func readTxOut(r io.Reader, limits ReadLimits) (*wire.TxOut, error) {
+	output := &wire.TxOut{}
+	if err := binary.Read(r, binary.LittleEndian, &output.Value); err != nil {
+		return nil, errors.Wrap(err, "value")
+	}
+
+	script, err := readVarScript(r, limits)
+	if err != nil {
+		return nil, errors.Wrap(err, "locking script")
+	}
+	output.LockingScript = script
+
+	return output, nil
+}
+
+func readVarScript(r io.Reader, limits ReadLimits) (bitcoin.Script, error) {
+	length, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "length")
+	}
+
+	return readScript(r, length, limits)
+}
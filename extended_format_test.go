@@ -0,0 +1,94 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_ExtendedFormat(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0),
+		bitcoin.Script{bitcoin.OP_TRUE}))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	tx.AddTxOut(wire.NewTxOut(900, parent.TxOut[0].LockingScript))
+	tx.LockTime = 500
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if err := itx.WriteExtendedFormat(&bytes.Buffer{}); errors.Cause(err) != ErrUnpromotedTx {
+		t.Fatalf("Wrong error for unpromoted tx : got %v, want %s", err, ErrUnpromotedTx)
+	}
+
+	itx, err = NewTransactionFromOutputs(ctx, *tx.TxHash(), tx, parent.TxOut, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.WriteExtendedFormat(buf); err != nil {
+		t.Fatalf("Failed to write extended format : %s", err)
+	}
+	b := buf.Bytes()
+
+	fromBytes, err := NewTransactionFromExtendedFormatBytes(ctx, b, true)
+	if err != nil {
+		t.Fatalf("Failed to read extended format : %s", err)
+	}
+
+	fromHex, err := NewTransactionFromExtendedFormat(ctx, hex.EncodeToString(b)+"\n", true)
+	if err != nil {
+		t.Fatalf("Failed to read extended format hex : %s", err)
+	}
+
+	for _, read := range []*Transaction{fromBytes, fromHex} {
+		if !read.Hash.Equal(tx.TxHash()) {
+			t.Fatalf("Wrong tx hash : got %s, want %s", read.Hash, tx.TxHash())
+		}
+
+		if !read.IsPromoted(ctx) {
+			t.Fatalf("Not promoted")
+		}
+
+		for i, input := range read.Inputs {
+			if input.Value != parent.TxOut[i].Value ||
+				!input.LockingScript.Equal(parent.TxOut[i].LockingScript) {
+				t.Fatalf("Wrong input %d : got %d %s, want %d %s", i, input.Value,
+					input.LockingScript, parent.TxOut[i].Value, parent.TxOut[i].LockingScript)
+			}
+		}
+
+		rewritten := &bytes.Buffer{}
+		if err := read.WriteExtendedFormat(rewritten); err != nil {
+			t.Fatalf("Failed to write extended format : %s", err)
+		}
+
+		if !bytes.Equal(rewritten.Bytes(), b) {
+			t.Fatalf("Wrong extended format : got %x, want %x", rewritten.Bytes(), b)
+		}
+	}
+
+	raw := &bytes.Buffer{}
+	tx.Serialize(raw)
+	if _, err := NewTransactionFromExtendedFormatBytes(ctx, raw.Bytes(), true); errors.Cause(err) !=
+		ErrNotExtendedFormat {
+		t.Fatalf("Wrong error for raw tx : got %v, want %s", err, ErrNotExtendedFormat)
+	}
+
+	if _, err := NewTransactionFromExtendedFormatBytes(ctx, b[:len(b)-2], true); err == nil {
+		t.Fatalf("Read truncated extended format")
+	}
+}
@@ -0,0 +1,143 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// FileNode is a NodeInterface that serves txs from a directory containing a file for each tx. The
+// file name is the tx hash and the contents are the hex of the raw tx, like the fixtures
+// directory.
+type FileNode struct {
+	dir string
+}
+
+// NewFileNode creates a FileNode for the directory, creating it if it doesn't exist.
+func NewFileNode(dir string) (*FileNode, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "create directory")
+	}
+
+	return &FileNode{
+		dir: dir,
+	}, nil
+}
+
+// SaveTx writes the tx to the directory.
+func (n *FileNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	buf := &bytes.Buffer{}
+	if err := tx.Serialize(buf); err != nil {
+		return errors.Wrap(err, "serialize")
+	}
+
+	hash := *tx.TxHash()
+
+	// Write to a temporary file and rename so a partial file is never visible. Each save has its
+	// own temporary file so concurrent saves of the same tx don't write to the same file.
+	file, err := os.CreateTemp(n.dir, hash.String()+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "create")
+	}
+	tempPath := file.Name()
+
+	if _, err := file.WriteString(hex.EncodeToString(buf.Bytes()) + "\n"); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return errors.Wrap(err, "write")
+	}
+
+	if err := file.Chmod(0644); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return errors.Wrap(err, "chmod")
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(tempPath)
+		return errors.Wrap(err, "close")
+	}
+
+	if err := os.Rename(tempPath, n.path(hash)); err != nil {
+		os.Remove(tempPath)
+		return errors.Wrap(err, "rename")
+	}
+
+	return nil
+}
+
+// GetTx reads the tx from the directory. It returns ErrTxNotFound if there is no file for the tx.
+func (n *FileNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	b, err := os.ReadFile(n.path(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrap(ErrTxNotFound, hash.String())
+		}
+		return nil, errors.Wrap(err, "read")
+	}
+
+	// Strip trailing spaces and newlines that editors might add.
+	raw, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode hex %s", hash)
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, errors.Wrapf(err, "deserialize %s", hash)
+	}
+
+	if !tx.TxHash().Equal(&hash) {
+		return nil, errors.Wrapf(ErrTxNotFound, "file %s contains tx %s", hash, tx.TxHash())
+	}
+
+	return tx, nil
+}
+
+// GetOutputs reads the outputs from the txs that contain them. It returns an
+// *OutputNotFoundError if the tx isn't in the directory or doesn't contain the output.
+func (n *FileNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	txs := make(map[bitcoin.Hash32]*wire.MsgTx)
+	result := make([]bitcoin.UTXO, len(outpoints))
+	for i, outpoint := range outpoints {
+		tx, exists := txs[outpoint.Hash]
+		if !exists {
+			var err error
+			tx, err = n.GetTx(ctx, outpoint.Hash)
+			if err != nil {
+				if errors.Cause(err) == ErrTxNotFound {
+					return nil, &OutputNotFoundError{OutPoint: outpoint}
+				}
+				return nil, errors.Wrapf(err, "outpoint %s", outpoint)
+			}
+			txs[outpoint.Hash] = tx
+		}
+
+		if int(outpoint.Index) >= len(tx.TxOut) {
+			return nil, &OutputNotFoundError{OutPoint: outpoint}
+		}
+
+		result[i] = bitcoin.UTXO{
+			Hash:          outpoint.Hash,
+			Index:         outpoint.Index,
+			Value:         tx.TxOut[outpoint.Index].Value,
+			LockingScript: tx.TxOut[outpoint.Index].LockingScript,
+		}
+	}
+
+	return result, nil
+}
+
+func (n *FileNode) path(hash bitcoin.Hash32) string {
+	return filepath.Join(n.dir, hash.String())
+}
@@ -0,0 +1,111 @@
+package inspector
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_FileNode(t *testing.T) {
+	ctx := context.Background()
+
+	fixtures, err := NewFileNode("fixtures")
+	if err != nil {
+		t.Fatalf("Failed to create fixtures node : %s", err)
+	}
+
+	hash := *newHash("2c68cf3e1216acaa1e274dfd3b665b6a9d1d1d252e68d190f9fffc5f7e11fd27")
+	tx, err := fixtures.GetTx(ctx, hash)
+	if err != nil {
+		t.Fatalf("Failed to get fixture tx : %s", err)
+	}
+
+	node, err := NewFileNode(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create node : %s", err)
+	}
+
+	if _, err := node.GetTx(ctx, hash); errors.Cause(err) != ErrTxNotFound {
+		t.Fatalf("Wrong missing tx error : got %v, want %v", err, ErrTxNotFound)
+	}
+
+	if err := node.SaveTx(ctx, tx); err != nil {
+		t.Fatalf("Failed to save tx : %s", err)
+	}
+
+	readTx, err := node.GetTx(ctx, hash)
+	if err != nil {
+		t.Fatalf("Failed to get saved tx : %s", err)
+	}
+	if !readTx.TxHash().Equal(&hash) {
+		t.Fatalf("Wrong tx hash : got %s, want %s", readTx.TxHash(), hash)
+	}
+
+	// Concurrent saves of the same tx each write their own temporary file.
+	var wait sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			errs[i] = node.SaveTx(ctx, tx)
+		}(i)
+	}
+	wait.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Failed to save tx %d : %s", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(node.dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory : %s", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != hash.String() {
+		t.Fatalf("Wrong files : got %d, want only %s", len(entries), hash)
+	}
+
+	if _, err := node.GetTx(ctx, hash); err != nil {
+		t.Fatalf("Failed to get saved tx : %s", err)
+	}
+
+	outpoints := []wire.OutPoint{
+		{Hash: hash, Index: 1},
+		{Hash: hash, Index: 0},
+	}
+	outputs, err := node.GetOutputs(ctx, outpoints)
+	if err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+	for i, output := range outputs {
+		if output.Index != outpoints[i].Index ||
+			output.Value != tx.TxOut[outpoints[i].Index].Value {
+			t.Fatalf("Wrong output %d : got %d %d", i, output.Index, output.Value)
+		}
+	}
+
+	missing := []wire.OutPoint{
+		{Hash: hash, Index: 0},
+		{Hash: hash, Index: uint32(len(tx.TxOut))},
+		{Hash: bitcoin.Hash32{1}, Index: 0},
+	}
+	for _, outpoint := range missing[1:] {
+		_, err := node.GetOutputs(ctx, []wire.OutPoint{missing[0], outpoint})
+		notFound, ok := errors.Cause(err).(*OutputNotFoundError)
+		if !ok {
+			t.Fatalf("Wrong missing output error : %v", err)
+		}
+		if notFound.OutPoint != outpoint {
+			t.Fatalf("Wrong missing outpoint : got %s, want %s", notFound.OutPoint, outpoint)
+		}
+		t.Logf("Missing output error : %s", err)
+	}
+}
@@ -0,0 +1,161 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// fuzzLimits keeps allocations small so the fuzzer finds limit violations quickly.
+var fuzzLimits = ReadLimits{
+	MaxInputCount: 1000,
+	MaxScriptSize: 1 << 16,
+	MaxSize:       1 << 20,
+}
+
+func FuzzTransactionRead(f *testing.F) {
+	for _, itx := range fixtureTransactions(f) {
+		for _, write := range []func(*Transaction, *bytes.Buffer) error{
+			func(itx *Transaction, buf *bytes.Buffer) error { return itx.Write(buf) },
+			func(itx *Transaction, buf *bytes.Buffer) error { return itx.Write_v3(buf) },
+			func(itx *Transaction, buf *bytes.Buffer) error { return itx.Write_v2(buf) },
+		} {
+			buf := &bytes.Buffer{}
+			if err := write(itx, buf); err != nil {
+				f.Fatalf("Failed to write fixture : %s", err)
+			}
+			f.Add(buf.Bytes())
+		}
+	}
+	f.Add(wrongParentRecord(f))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		opts := DefaultParseOptions(true)
+		opts.ReadLimits = fuzzLimits
+
+		itx := &Transaction{}
+		if err := itx.ReadWithOptions(bytes.NewReader(b), opts); err != nil {
+			return
+		}
+
+		if len(itx.Inputs) > int(fuzzLimits.MaxInputCount) {
+			t.Fatalf("Input count exceeds limit : %d", len(itx.Inputs))
+		}
+
+		// Inputs with unknown spent outputs, such as from a v0 record with a wrong parent, can't
+		// be written.
+		buf := &bytes.Buffer{}
+		err := itx.Write(buf)
+		if len(itx.UnknownInputs()) > 0 {
+			if errors.Cause(err) != ErrIncompleteTx {
+				t.Fatalf("Wrong write error for unknown inputs : got %v, want %s", err,
+					ErrIncompleteTx)
+			}
+		} else if err != nil {
+			t.Fatalf("Failed to write read tx : %s", err)
+		}
+	})
+}
+
+func FuzzNewTransaction(f *testing.F) {
+	for _, raw := range fixtureHex(f) {
+		f.Add(raw)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		opts := DefaultParseOptions(true)
+		opts.ReadLimits = fuzzLimits
+
+		NewTransactionWithOptions(context.Background(), raw, opts)
+	})
+}
+
+func FuzzInputRead(f *testing.F) {
+	for _, itx := range fixtureTransactions(f) {
+		for _, input := range itx.Inputs {
+			buf := &bytes.Buffer{}
+			if err := input.Write(buf); err != nil {
+				f.Fatalf("Failed to write input : %s", err)
+			}
+			f.Add(uint8(3), buf.Bytes())
+
+			buf = &bytes.Buffer{}
+			if err := input.Write_v2(buf); err != nil {
+				f.Fatalf("Failed to write input : %s", err)
+			}
+			f.Add(uint8(2), buf.Bytes())
+		}
+
+		buf := &bytes.Buffer{}
+		if err := itx.MsgTx.Serialize(buf); err != nil {
+			f.Fatalf("Failed to write tx : %s", err)
+		}
+		f.Add(uint8(0), buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, version uint8, b []byte) {
+		input := &Input{}
+		if err := input.ReadWithLimits(version, bytes.NewReader(b), fuzzLimits); err != nil {
+			return
+		}
+
+		if uint64(len(input.LockingScript)) > fuzzLimits.MaxScriptSize {
+			t.Fatalf("Script size exceeds limit : %d", len(input.LockingScript))
+		}
+	})
+}
+
+// fixtureHex returns the raw hex txs in the fixtures directory.
+func fixtureHex(tb testing.TB) []string {
+	entries, err := os.ReadDir("fixtures")
+	if err != nil {
+		tb.Fatalf("Failed to read fixtures : %s", err)
+	}
+
+	var result []string
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join("fixtures", entry.Name()))
+		if err != nil {
+			tb.Fatalf("Failed to read fixture : %s", err)
+		}
+		result = append(result, strings.Trim(string(b), "\n "))
+	}
+
+	return result
+}
+
+// fixtureTransactions returns the txs in the fixtures directory promoted with fake spent outputs.
+func fixtureTransactions(tb testing.TB) []*Transaction {
+	ctx := context.Background()
+
+	var result []*Transaction
+	for _, raw := range fixtureHex(tb) {
+		b, err := hex.DecodeString(raw)
+		if err != nil {
+			tb.Fatalf("Failed to decode fixture hex : %s", err)
+		}
+
+		tx := decodeTX(b)
+		outputs := make([]*wire.TxOut, len(tx.TxIn))
+		for i := range outputs {
+			outputs[i] = wire.NewTxOut(uint64(1000+i), tx.TxOut[0].LockingScript)
+		}
+
+		itx, err := NewTransactionFromOutputs(ctx, *tx.TxHash(), &tx, outputs, true)
+		if err != nil {
+			tb.Fatalf("Failed to create fixture tx : %s", err)
+		}
+
+		result = append(result, itx)
+	}
+
+	return result
+}
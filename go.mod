@@ -6,9 +6,13 @@ toolchain go1.23.1
 
 require (
 	github.com/pkg/errors v0.9.1
+	github.com/tokenized/bitcoin_interpreter v0.1.1
+	github.com/tokenized/envelope v1.1.0
 	github.com/tokenized/logger v0.1.4-0.20230915152315-06e93587a3c5
 	github.com/tokenized/pkg v0.7.1-0.20240625144724-c2bd2bb2fe8f
 	github.com/tokenized/specification v1.3.2-0.20240708131147-1729b8940b2a
+	github.com/tokenized/txbuilder v1.1.1-0.20230816003850-4414c86d5db4
+	google.golang.org/protobuf v1.34.0
 )
 
 require (
@@ -22,13 +26,9 @@ require (
 	github.com/golang/protobuf v1.5.0 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/gorilla/websocket v1.5.0 // indirect
-	github.com/tokenized/bitcoin_interpreter v0.1.1 // indirect
 	github.com/tokenized/channels v0.1.1 // indirect
-	github.com/tokenized/envelope v1.1.0 // indirect
 	github.com/tokenized/threads v0.1.2 // indirect
-	github.com/tokenized/txbuilder v1.1.1-0.20230816003850-4414c86d5db4 // indirect
 	github.com/tyler-smith/go-bip32 v1.0.0 // indirect
 	golang.org/x/crypto v0.22.0 // indirect
 	golang.org/x/sys v0.19.0 // indirect
-	google.golang.org/protobuf v1.34.0 // indirect
 )
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"fmt"
 	"strings"
 
 	"github.com/tokenized/pkg/bitcoin"
@@ -35,10 +36,16 @@ var (
 	// ErrMissingOutputs
 	ErrMissingOutputs = errors.New("Message is missing outputs")
 
+	// ErrInvalidChecksum means the checksum of serialized data doesn't match.
+	ErrInvalidChecksum = errors.New("Invalid checksum")
+
 	ErrNegativeFee  = errors.New("Negative fee")
 	ErrUnpromotedTx = errors.New("Unpromoted tx")
 	ErrIncompleteTx = errors.New("Incomplete tx")
 
+	// ErrTxNotFound means a node doesn't have the requested tx.
+	ErrTxNotFound = errors.New("Tx not found")
+
 	// prefixP2PKH Pay to PKH prefix
 	prefixP2PKH = []byte{0x76, 0xA9}
 )
@@ -51,8 +58,127 @@ type NodeInterface interface {
 	GetOutputs(context.Context, []wire.OutPoint) ([]bitcoin.UTXO, error)
 }
 
+// OutputNotFoundError is returned by GetOutputs when a node can't provide one of the outputs.
+type OutputNotFoundError struct {
+	OutPoint wire.OutPoint
+}
+
+func (e *OutputNotFoundError) Error() string {
+	return fmt.Sprintf("Output not found : %s", e.OutPoint)
+}
+
+// MissingParentsError is returned by PromoteFromParents when parents of inputs weren't provided.
+type MissingParentsError struct {
+	Hashes []bitcoin.Hash32
+}
+
+func (e *MissingParentsError) Error() string {
+	hashes := make([]string, len(e.Hashes))
+	for i, hash := range e.Hashes {
+		hashes[i] = hash.String()
+	}
+
+	return fmt.Sprintf("%s : %s", ErrMissingParentTx, strings.Join(hashes, ", "))
+}
+
+// Cause returns ErrMissingParentTx so errors.Cause finds it.
+func (e *MissingParentsError) Cause() error {
+	return ErrMissingParentTx
+}
+
+// verifyOutputs returns an error unless there is one output for each outpoint, in the same order.
+func verifyOutputs(outpoints []wire.OutPoint, outputs []bitcoin.UTXO) error {
+	if len(outputs) != len(outpoints) {
+		return fmt.Errorf("Wrong output count : got %d, want %d", len(outputs), len(outpoints))
+	}
+
+	for i, output := range outputs {
+		if !output.Hash.Equal(&outpoints[i].Hash) || output.Index != outpoints[i].Index {
+			return fmt.Errorf("Wrong output %d : got %s:%d, want %s", i, output.Hash,
+				output.Index, outpoints[i])
+		}
+	}
+
+	return nil
+}
+
+// getRequestedOutputs requests the outpoints needed by several requesters from the node.
+// requesters contains the indexes, in errs, of the requesters needing each outpoint. When an
+// outpoint isn't found only the requesters needing it fail, and the outpoints still needed by the
+// others are requested again. The errors are set for the requesters that failed.
+func getRequestedOutputs(ctx context.Context, node NodeInterface, outpoints []wire.OutPoint,
+	requesters map[wire.OutPoint][]int, errs []error) map[wire.OutPoint]bitcoin.UTXO {
+
+	result := make(map[wire.OutPoint]bitcoin.UTXO, len(outpoints))
+	for len(outpoints) > 0 {
+		outputs, err := node.GetOutputs(ctx, outpoints)
+		if err == nil {
+			err = verifyOutputs(outpoints, outputs)
+		}
+
+		if err == nil {
+			for i, output := range outputs {
+				result[outpoints[i]] = output
+			}
+			return result
+		}
+
+		notFound, ok := errors.Cause(err).(*OutputNotFoundError)
+		if !ok || len(requesters[notFound.OutPoint]) == 0 {
+			// Retrying won't help, so all the requesters still waiting fail.
+			failRequesters(outpoints, requesters, errs, err)
+			return result
+		}
+
+		for _, i := range requesters[notFound.OutPoint] {
+			errs[i] = err
+		}
+
+		// Only request the outpoints still needed by requesters that haven't failed.
+		var remaining []wire.OutPoint
+		for _, outpoint := range outpoints {
+			for _, i := range requesters[outpoint] {
+				if errs[i] == nil {
+					remaining = append(remaining, outpoint)
+					break
+				}
+			}
+		}
+
+		if len(remaining) == len(outpoints) {
+			// The outpoint not found wasn't requested, so retrying would return the same error.
+			failRequesters(remaining, requesters, errs, err)
+			return result
+		}
+
+		outpoints = remaining
+	}
+
+	return result
+}
+
+// failRequesters sets the error for the requesters of the outpoints that haven't already failed.
+func failRequesters(outpoints []wire.OutPoint, requesters map[wire.OutPoint][]int, errs []error,
+	err error) {
+
+	for _, outpoint := range outpoints {
+		for _, i := range requesters[outpoint] {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+	}
+}
+
 // NewTransaction builds an ITX from a raw transaction.
 func NewTransaction(ctx context.Context, raw string, isTest bool) (*Transaction, error) {
+	return NewTransactionWithOptions(ctx, raw, DefaultParseOptions(isTest))
+}
+
+// NewTransactionWithOptions builds an ITX from a raw transaction.
+func NewTransactionWithOptions(ctx context.Context, raw string,
+	opts ParseOptions) (*Transaction, error) {
+
 	data := strings.Trim(string(raw), "\n ")
 
 	b, err := hex.DecodeString(data)
@@ -60,39 +186,65 @@ func NewTransaction(ctx context.Context, raw string, isTest bool) (*Transaction,
 		return nil, errors.Wrap(ErrDecodeFail, "decoding string")
 	}
 
+	limits := opts.ReadLimits.orDefault()
+	if uint64(len(b)) > limits.MaxSize {
+		return nil, errors.Wrapf(ErrSizeLimit, "%d > %d", len(b), limits.MaxSize)
+	}
+
 	// Set up the Wire transaction
-	tx := wire.MsgTx{}
-	buf := bytes.NewReader(b)
-	if err := tx.Deserialize(buf); err != nil {
-		return nil, errors.Wrap(ErrDecodeFail, "deserializing wire message")
+	tx, err := readMsgTx(bytes.NewReader(b), limits)
+	if err != nil {
+		return nil, decodeError(err, "deserializing wire message")
 	}
 
-	return NewTransactionFromWire(ctx, &tx, isTest)
+	return NewTransactionFromWireWithOptions(ctx, tx, opts)
 }
 
 // NewTransactionFromHash builds an ITX from a transaction hash
 func NewTransactionFromHash(ctx context.Context, node NodeInterface, hash bitcoin.Hash32,
 	isTest bool) (*Transaction, error) {
 
+	return NewTransactionFromHashWithOptions(ctx, node, hash, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromHashWithOptions builds an ITX from a transaction hash
+func NewTransactionFromHashWithOptions(ctx context.Context, node NodeInterface,
+	hash bitcoin.Hash32, opts ParseOptions) (*Transaction, error) {
+
 	tx, err := node.GetTx(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewTransactionFromHashWire(ctx, hash, tx, isTest)
+	return NewTransactionFromHashWireWithOptions(ctx, hash, tx, opts)
 }
 
 // NewTransactionFromWire builds an ITX from a wire Msg Tx
 func NewTransactionFromWire(ctx context.Context, tx *wire.MsgTx,
 	isTest bool) (*Transaction, error) {
 
-	return NewTransactionFromHashWire(ctx, *tx.TxHash(), tx, isTest)
+	return NewTransactionFromWireWithOptions(ctx, tx, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromWireWithOptions builds an ITX from a wire Msg Tx
+func NewTransactionFromWireWithOptions(ctx context.Context, tx *wire.MsgTx,
+	opts ParseOptions) (*Transaction, error) {
+
+	return NewTransactionFromHashWireWithOptions(ctx, *tx.TxHash(), tx, opts)
 }
 
 // NewTransactionFromWire builds an ITX from a wire Msg Tx
 func NewTransactionFromHashWire(ctx context.Context, hash bitcoin.Hash32, tx *wire.MsgTx,
 	isTest bool) (*Transaction, error) {
 
+	return NewTransactionFromHashWireWithOptions(ctx, hash, tx, DefaultParseOptions(isTest))
+}
+
+// NewTransactionFromHashWireWithOptions builds an ITX from an already calculated tx hash and a
+// wire Msg Tx
+func NewTransactionFromHashWireWithOptions(ctx context.Context, hash bitcoin.Hash32,
+	tx *wire.MsgTx, opts ParseOptions) (*Transaction, error) {
+
 	// Must have inputs
 	if len(tx.TxIn) == 0 {
 		return nil, errors.Wrap(ErrMissingInputs, "parsing transaction")
@@ -109,7 +261,7 @@ func NewTransactionFromHashWire(ctx context.Context, hash bitcoin.Hash32, tx *wi
 		MsgTx: &txc,
 	}
 
-	if err := result.Setup(ctx, isTest); err != nil {
+	if err := result.SetupWithOptions(ctx, opts); err != nil {
 		return nil, errors.Wrap(err, "setup")
 	}
 
@@ -141,7 +293,14 @@ func NewBaseTransactionFromWire(ctx context.Context, tx *wire.MsgTx) (*Transacti
 func NewTransactionFromTransactionWithOutputs(ctx context.Context,
 	tx expanded_tx.TransactionWithOutputs, isTest bool) (*Transaction, error) {
 
-	result, err := NewTransactionFromWire(ctx, tx.GetMsgTx(), isTest)
+	return NewTransactionFromTransactionWithOutputsWithOptions(ctx, tx,
+		DefaultParseOptions(isTest))
+}
+
+func NewTransactionFromTransactionWithOutputsWithOptions(ctx context.Context,
+	tx expanded_tx.TransactionWithOutputs, opts ParseOptions) (*Transaction, error) {
+
+	result, err := NewTransactionFromWireWithOptions(ctx, tx.GetMsgTx(), opts)
 	if err != nil {
 		return result, errors.Wrap(err, "new from wire")
 	}
@@ -166,7 +325,7 @@ func NewTransactionFromTransactionWithOutputs(ctx context.Context,
 		})
 	}
 
-	if err := result.PromoteFromUTXOs(ctx, utxos, isTest); err != nil {
+	if err := result.PromoteFromUTXOsWithOptions(ctx, utxos, opts); err != nil {
 		return result, errors.Wrap(err, "promote")
 	}
 
@@ -176,6 +335,13 @@ func NewTransactionFromTransactionWithOutputs(ctx context.Context,
 func NewTransactionFromOutputs(ctx context.Context, hash bitcoin.Hash32, tx *wire.MsgTx,
 	outputs []*wire.TxOut, isTest bool) (*Transaction, error) {
 
+	return NewTransactionFromOutputsWithOptions(ctx, hash, tx, outputs,
+		DefaultParseOptions(isTest))
+}
+
+func NewTransactionFromOutputsWithOptions(ctx context.Context, hash bitcoin.Hash32,
+	tx *wire.MsgTx, outputs []*wire.TxOut, opts ParseOptions) (*Transaction, error) {
+
 	result, err := NewBaseTransactionFromHashWire(ctx, hash, tx)
 	if err != nil {
 		return nil, errors.Wrap(err, "new")
@@ -199,7 +365,7 @@ func NewTransactionFromOutputs(ctx context.Context, hash bitcoin.Hash32, tx *wir
 		})
 	}
 
-	if err := result.PromoteFromUTXOs(ctx, utxos, isTest); err != nil {
+	if err := result.PromoteFromUTXOsWithOptions(ctx, utxos, opts); err != nil {
 		return nil, errors.Wrap(err, "promote")
 	}
 
@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/tokenized/pkg/bitcoin"
-	"github.com/tokenized/pkg/wire"
 )
 
 func TestParseTX(t *testing.T) {
@@ -58,17 +57,3 @@ func TestParseTX(t *testing.T) {
 	// 	t.Fatalf("\t%s\tShould get the expected result. Diff:\n%s", "\u2717", diff)
 	// }
 }
-
-type TestNode struct{}
-
-func (n *TestNode) GetTx(context.Context, *bitcoin.Hash32) (*wire.MsgTx, error) {
-	return nil, nil
-}
-
-func (n *TestNode) GetOutputs(context.Context, []wire.OutPoint) ([]bitcoin.UTXO, error) {
-	return nil, nil
-}
-
-func (n *TestNode) SaveTx(context.Context, *wire.MsgTx) error {
-	return nil
-}
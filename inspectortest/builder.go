@@ -0,0 +1,142 @@
+package inspectortest
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+	"github.com/tokenized/txbuilder"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FeeRate is the fee rate, in satoshis per byte, of the txs created.
+	FeeRate = 0.5
+
+	// DustFeeRate is the fee rate used to calculate dust limits of the txs created.
+	DustFeeRate = 0.25
+
+	// fundingFee is added to the value of the input of a parent tx to pay its fee.
+	fundingFee = 10000
+)
+
+// NewParentTx creates a tx signed by the key that pays each value to the key, followed by a change
+// output to the key. It spends a random outpoint, so it can't be promoted itself.
+func NewParentTx(key bitcoin.Key, values ...uint64) (*wire.MsgTx, error) {
+	lockingScript, err := key.LockingScript()
+	if err != nil {
+		return nil, errors.Wrap(err, "locking script")
+	}
+
+	var outpoint wire.OutPoint
+	if _, err := rand.Read(outpoint.Hash[:]); err != nil {
+		return nil, errors.Wrap(err, "random hash")
+	}
+
+	total := uint64(fundingFee)
+	for _, value := range values {
+		total += value
+	}
+
+	tx := txbuilder.NewTxBuilder(FeeRate, DustFeeRate)
+	if err := tx.AddInput(outpoint, lockingScript, total); err != nil {
+		return nil, errors.Wrap(err, "add input")
+	}
+
+	for i, value := range values {
+		if err := tx.AddOutput(lockingScript, value, false, false); err != nil {
+			return nil, errors.Wrapf(err, "add output %d", i)
+		}
+	}
+
+	if err := tx.AddOutput(lockingScript, 0, true, true); err != nil {
+		return nil, errors.Wrap(err, "add change")
+	}
+
+	if _, err := tx.Sign([]bitcoin.Key{key}); err != nil {
+		return nil, errors.Wrap(err, "sign")
+	}
+
+	return tx.MsgTx, nil
+}
+
+// NewChildTx creates a tx signed by the key that spends every output of the parents that is locked
+// to the key. It contains an output for each action, followed by a change output to the key.
+func NewChildTx(key bitcoin.Key, parents []*wire.MsgTx, isTest bool,
+	acts ...actions.Action) (*wire.MsgTx, error) {
+
+	lockingScript, err := key.LockingScript()
+	if err != nil {
+		return nil, errors.Wrap(err, "locking script")
+	}
+
+	tx := txbuilder.NewTxBuilder(FeeRate, DustFeeRate)
+	for _, parent := range parents {
+		hash := parent.TxHash()
+		for index, output := range parent.TxOut {
+			if !output.LockingScript.Equal(lockingScript) {
+				continue
+			}
+
+			if err := tx.AddInput(*wire.NewOutPoint(hash, uint32(index)), lockingScript,
+				output.Value); err != nil {
+				return nil, errors.Wrapf(err, "add input %s:%d", hash, index)
+			}
+		}
+	}
+
+	for i, action := range acts {
+		script, err := protocol.Serialize(action, isTest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "serialize action %d", i)
+		}
+
+		if err := tx.AddOutput(script, 0, false, false); err != nil {
+			return nil, errors.Wrapf(err, "add action %d", i)
+		}
+	}
+
+	if err := tx.AddOutput(lockingScript, 0, true, true); err != nil {
+		return nil, errors.Wrap(err, "add change")
+	}
+
+	if _, err := tx.Sign([]bitcoin.Key{key}); err != nil {
+		return nil, errors.Wrap(err, "sign")
+	}
+
+	return tx.MsgTx, nil
+}
+
+// NewTransaction creates a parent tx and a child tx containing the actions, adds them to the node,
+// and returns the child as a promoted inspector transaction.
+func NewTransaction(ctx context.Context, node *Node, key bitcoin.Key, isTest bool,
+	acts ...actions.Action) (*inspector.Transaction, error) {
+
+	parent, err := NewParentTx(key, 100000)
+	if err != nil {
+		return nil, errors.Wrap(err, "parent")
+	}
+	node.AddTx(parent)
+
+	child, err := NewChildTx(key, []*wire.MsgTx{parent}, isTest, acts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "child")
+	}
+	node.AddTx(child)
+
+	itx, err := inspector.NewTransactionFromWire(ctx, child, isTest)
+	if err != nil {
+		return nil, errors.Wrap(err, "inspect")
+	}
+
+	if err := itx.Promote(ctx, node, isTest); err != nil {
+		return nil, errors.Wrap(err, "promote")
+	}
+
+	return itx, nil
+}
@@ -0,0 +1,72 @@
+package inspectortest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/bitcoin_interpreter"
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+
+	"github.com/pkg/errors"
+)
+
+func Test_NewTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := bitcoin.GenerateKey(bitcoin.TestNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+
+	node := NewNode()
+	itx, err := NewTransaction(ctx, node, key, true,
+		&actions.ContractOffer{ContractName: "Test"})
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if !itx.IsPromoted(ctx) {
+		t.Fatalf("Transaction not promoted")
+	}
+
+	if !itx.IsRequest() {
+		t.Fatalf("Transaction should contain a request action")
+	}
+
+	if _, ok := itx.Outputs[0].Action.(*actions.ContractOffer); !ok {
+		t.Fatalf("First output should contain the contract offer")
+	}
+
+	if err := bitcoin_interpreter.VerifyTx(ctx, itx); err != nil {
+		t.Fatalf("Failed to verify signatures : %s", err)
+	}
+
+	fee, err := itx.Fee()
+	if err != nil {
+		t.Fatalf("Failed to get fee : %s", err)
+	}
+	t.Logf("Fee : %d", fee)
+
+	if calls := node.Calls(); calls.GetOutputs != 1 {
+		t.Fatalf("Wrong GetOutputs calls : got %d, want %d", calls.GetOutputs, 1)
+	}
+}
+
+func Test_Node_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	node := NewNode()
+	if _, err := node.GetTx(ctx, bitcoin.Hash32{1}); errors.Cause(err) != inspector.ErrTxNotFound {
+		t.Fatalf("Wrong missing tx error : got %v, want %v", err, inspector.ErrTxNotFound)
+	}
+
+	outpoint := wire.OutPoint{Hash: bitcoin.Hash32{1}, Index: 2}
+	_, err := node.GetOutputs(ctx, []wire.OutPoint{outpoint})
+	notFound, ok := err.(*inspector.OutputNotFoundError)
+	if !ok || notFound.OutPoint != outpoint {
+		t.Fatalf("Wrong missing output error : %v", err)
+	}
+}
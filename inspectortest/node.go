@@ -0,0 +1,110 @@
+package inspectortest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// Node is an in-memory inspector.NodeInterface for tests. It counts calls so tests can verify how
+// a node is used.
+type Node struct {
+	txs   map[bitcoin.Hash32]*wire.MsgTx
+	calls NodeCalls
+
+	lock sync.Mutex
+}
+
+// NodeCalls are the number of calls made to each Node function.
+type NodeCalls struct {
+	SaveTx     int
+	GetTx      int
+	GetOutputs int
+
+	// OutPoints is the total number of outpoints requested from GetOutputs.
+	OutPoints int
+}
+
+// NewNode creates a Node containing the txs.
+func NewNode(txs ...*wire.MsgTx) *Node {
+	result := &Node{
+		txs: make(map[bitcoin.Hash32]*wire.MsgTx),
+	}
+
+	for _, tx := range txs {
+		result.txs[*tx.TxHash()] = tx
+	}
+
+	return result
+}
+
+// Calls returns the number of calls made to the node.
+func (n *Node) Calls() NodeCalls {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.calls
+}
+
+// AddTx adds the tx without counting it as a SaveTx call.
+func (n *Node) AddTx(tx *wire.MsgTx) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.txs[*tx.TxHash()] = tx
+}
+
+func (n *Node) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.calls.SaveTx++
+	n.txs[*tx.TxHash()] = tx
+	return nil
+}
+
+// GetTx returns the tx or inspector.ErrTxNotFound.
+func (n *Node) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.calls.GetTx++
+	tx, exists := n.txs[hash]
+	if !exists {
+		return nil, errors.Wrap(inspector.ErrTxNotFound, hash.String())
+	}
+
+	return tx, nil
+}
+
+// GetOutputs returns the outputs or an *inspector.OutputNotFoundError.
+func (n *Node) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.calls.GetOutputs++
+	n.calls.OutPoints += len(outpoints)
+
+	result := make([]bitcoin.UTXO, len(outpoints))
+	for i, outpoint := range outpoints {
+		tx, exists := n.txs[outpoint.Hash]
+		if !exists || int(outpoint.Index) >= len(tx.TxOut) {
+			return nil, &inspector.OutputNotFoundError{OutPoint: outpoint}
+		}
+
+		result[i] = bitcoin.UTXO{
+			Hash:          outpoint.Hash,
+			Index:         outpoint.Index,
+			Value:         tx.TxOut[outpoint.Index].Value,
+			LockingScript: tx.TxOut[outpoint.Index].LockingScript,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,206 @@
+package inspector
+
+import (
+	"fmt"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/json"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+
+	"github.com/pkg/errors"
+)
+
+// jsonTransaction is the JSON form of a Transaction.
+type jsonTransaction struct {
+	Hash       bitcoin.Hash32  `json:"hash"`
+	Tx         *wire.MsgTx     `json:"tx"` // marshals as hex bytes
+	Inputs     []*jsonInput    `json:"inputs,omitempty"`
+	Outputs    []*jsonOutput   `json:"outputs,omitempty"`
+	RejectCode uint32          `json:"reject_code,omitempty"`
+	RejectText string          `json:"reject_text,omitempty"`
+	Network    bitcoin.Network `json:"network,omitempty"`
+	IsTest     bool            `json:"is_test,omitempty"`
+}
+
+type jsonInput struct {
+	Value         uint64         `json:"value"`
+	LockingScript bitcoin.Script `json:"locking_script"`
+	Action        *jsonAction    `json:"action,omitempty"`
+	DecodeError   string         `json:"decode_error,omitempty"`
+	Unknown       bool           `json:"unknown,omitempty"`
+}
+
+type jsonOutput struct {
+	Action      *jsonAction `json:"action,omitempty"`
+	DecodeError string      `json:"decode_error,omitempty"`
+}
+
+// jsonAction is an action tagged with its action code so it can be unmarshalled into the correct
+// type.
+type jsonAction struct {
+	Code   string          `json:"code"`
+	Action json.RawMessage `json:"action"`
+}
+
+// MarshalJSON converts to json. Payloads are not included since they are decoder specific.
+func (itx *Transaction) MarshalJSON() ([]byte, error) {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	js := &jsonTransaction{
+		Hash:       itx.Hash,
+		Tx:         itx.MsgTx,
+		RejectCode: itx.RejectCode,
+		RejectText: itx.RejectText,
+		IsTest:     itx.IsTest,
+	}
+
+	if itx.Network != bitcoin.InvalidNet {
+		js.Network = itx.Network
+	}
+
+	for i, input := range itx.Inputs {
+		action, err := newJSONAction(input.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input %d", i)
+		}
+
+		js.Inputs = append(js.Inputs, &jsonInput{
+			Value:         input.Value,
+			LockingScript: input.LockingScript,
+			Action:        action,
+			DecodeError:   errorString(input.DecodeError),
+			Unknown:       input.Unknown,
+		})
+	}
+
+	for i, output := range itx.Outputs {
+		action, err := newJSONAction(output.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "output %d", i)
+		}
+
+		js.Outputs = append(js.Outputs, &jsonOutput{
+			Action:      action,
+			DecodeError: errorString(output.DecodeError),
+		})
+	}
+
+	return json.Marshal(js)
+}
+
+// UnmarshalJSON converts from json. The actions are unmarshalled from the json rather than decoded
+// from the scripts so the result doesn't depend on parse options.
+func (itx *Transaction) UnmarshalJSON(data []byte) error {
+	js := &jsonTransaction{}
+	if err := json.Unmarshal(data, js); err != nil {
+		return err
+	}
+
+	if js.Tx == nil {
+		return errors.New("Missing tx")
+	}
+
+	hash := js.Tx.TxHash()
+	if !js.Hash.IsZero() && !hash.Equal(&js.Hash) {
+		return fmt.Errorf("Wrong tx hash : got %s, want %s", js.Hash, hash)
+	}
+
+	if len(js.Outputs) != 0 && len(js.Outputs) != len(js.Tx.TxOut) {
+		return fmt.Errorf("Wrong output count : got %d, want %d", len(js.Outputs),
+			len(js.Tx.TxOut))
+	}
+
+	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	itx.Hash = *hash
+	itx.MsgTx = js.Tx
+	itx.RejectCode = js.RejectCode
+	itx.RejectText = js.RejectText
+	itx.Network = js.Network
+	itx.IsTest = js.IsTest
+
+	itx.Inputs = nil
+	for i, jsInput := range js.Inputs {
+		action, err := jsInput.Action.action()
+		if err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+
+		itx.Inputs = append(itx.Inputs, &Input{
+			Value:         jsInput.Value,
+			LockingScript: jsInput.LockingScript,
+			Action:        action,
+			ProtocolIDs:   envelopeProtocolIDs(jsInput.LockingScript),
+			DecodeError:   stringError(jsInput.DecodeError),
+			Unknown:       jsInput.Unknown,
+		})
+	}
+
+	itx.Outputs = nil
+	for i, jsOutput := range js.Outputs {
+		action, err := jsOutput.Action.action()
+		if err != nil {
+			return errors.Wrapf(err, "output %d", i)
+		}
+
+		itx.Outputs = append(itx.Outputs, &Output{
+			Action:      action,
+			ProtocolIDs: envelopeProtocolIDs(js.Tx.TxOut[i].LockingScript),
+			DecodeError: stringError(jsOutput.DecodeError),
+		})
+	}
+
+	return nil
+}
+
+func newJSONAction(action actions.Action) (*jsonAction, error) {
+	if action == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(action)
+	if err != nil {
+		return nil, errors.Wrap(err, "action")
+	}
+
+	return &jsonAction{
+		Code:   action.Code(),
+		Action: b,
+	}, nil
+}
+
+func (ja *jsonAction) action() (actions.Action, error) {
+	if ja == nil {
+		return nil, nil
+	}
+
+	result := actions.NewActionFromCode(ja.Code)
+	if result == nil {
+		return nil, fmt.Errorf("Unknown action code : %s", ja.Code)
+	}
+
+	if len(ja.Action) > 0 {
+		if err := json.Unmarshal(ja.Action, result); err != nil {
+			return nil, errors.Wrapf(err, "action %s", ja.Code)
+		}
+	}
+
+	return result, nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func stringError(s string) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return errors.New(s)
+}
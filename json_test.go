@@ -0,0 +1,122 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/json"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func Test_Transaction_JSON(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+	ra, err := key.RawAddress()
+	if err != nil {
+		t.Fatalf("Failed to create address : %s", err)
+	}
+
+	transfer := &actions.Transfer{
+		Instruments: []*actions.InstrumentTransferField{
+			{
+				InstrumentType: "CCY",
+				InstrumentCode: make([]byte, 20),
+				InstrumentSenders: []*actions.QuantityIndexField{
+					{Index: 0, Quantity: 1000},
+				},
+				InstrumentReceivers: []*actions.InstrumentReceiverField{
+					{Address: ra.Bytes(), Quantity: 1000},
+				},
+			},
+		},
+		OfferExpiry: 123456789,
+	}
+
+	script, err := protocol.Serialize(transfer, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+	tx := newTestTx(t, script)
+	tx.AddTxOut(wire.NewTxOut(0, newMalformedActionScript(t, true)))
+
+	outputs := []*wire.TxOut{wire.NewTxOut(2000, tx.TxOut[0].LockingScript)}
+	itx, err := NewTransactionFromOutputs(ctx, *tx.TxHash(), tx, outputs, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+	itx.RejectCode = actions.RejectionsInsufficientQuantity
+	itx.RejectText = "Not enough tokens"
+
+	js, err := json.MarshalIndent(itx, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal json : %s", err)
+	}
+
+	t.Logf("JSON : %s", js)
+
+	readTx := &Transaction{}
+	if err := json.Unmarshal(js, readTx); err != nil {
+		t.Fatalf("Failed to unmarshal json : %s", err)
+	}
+
+	if err := itx.Equal(readTx); err != nil {
+		t.Fatalf("Unmarshalled tx not equal : %s", err)
+	}
+
+	if readTx.RejectText != itx.RejectText {
+		t.Fatalf("Wrong reject text : got %s, want %s", readTx.RejectText, itx.RejectText)
+	}
+
+	if readTx.Network != itx.Network || readTx.IsTest != itx.IsTest {
+		t.Fatalf("Wrong parse metadata : got %s %t, want %s %t", readTx.Network, readTx.IsTest,
+			itx.Network, itx.IsTest)
+	}
+
+	if readTx.Outputs[1].Action.Code() != actions.CodeTransfer {
+		t.Fatalf("Wrong action code : got %s, want %s", readTx.Outputs[1].Action.Code(),
+			actions.CodeTransfer)
+	}
+
+	if len(readTx.DecodeDiagnostics()) != 1 {
+		t.Fatalf("Decode diagnostics not retained")
+	}
+}
+
+func Test_Transaction_JSON_Unknown(t *testing.T) {
+	ctx := context.Background()
+
+	tx := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN})
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if err := itx.PromotePartialFromUTXOs(ctx, nil, true); err != nil {
+		t.Fatalf("Failed to promote : %s", err)
+	}
+
+	js, err := json.Marshal(itx)
+	if err != nil {
+		t.Fatalf("Failed to marshal json : %s", err)
+	}
+
+	readTx := &Transaction{}
+	if err := json.Unmarshal(js, readTx); err != nil {
+		t.Fatalf("Failed to unmarshal json : %s", err)
+	}
+
+	if unknown := readTx.UnknownInputs(); len(unknown) != 1 || unknown[0] != 0 {
+		t.Fatalf("Wrong unknown inputs : got %v, want [0]", unknown)
+	}
+
+	if readTx.IsPromoted(ctx) {
+		t.Fatalf("Tx with unknown inputs should not be promoted")
+	}
+}
@@ -0,0 +1,218 @@
+package inspector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInputCountLimit means serialized data contains more inputs than allowed.
+	ErrInputCountLimit = errors.New("Input count exceeds limit")
+
+	// ErrScriptSizeLimit means serialized data contains a script larger than allowed.
+	ErrScriptSizeLimit = errors.New("Script size exceeds limit")
+
+	// ErrSizeLimit means serialized data is larger than allowed.
+	ErrSizeLimit = errors.New("Data size exceeds limit")
+)
+
+// ReadLimits bounds the serialized data accepted by Read so that corrupt or hostile data can't
+// cause large allocations. Zero values mean the default limit.
+type ReadLimits struct {
+	// MaxInputCount is the maximum number of inputs in the tx and in the serialized input list.
+	MaxInputCount uint32
+
+	// MaxScriptSize is the maximum size of any single script.
+	MaxScriptSize uint64
+
+	// MaxSize is the maximum total size of the serialized transaction.
+	MaxSize uint64
+}
+
+// DefaultReadLimits returns the limits used when none are specified. They are large enough for any
+// transaction accepted by the network, so memory use is bounded by the size of the data actually
+// provided rather than by the limits.
+func DefaultReadLimits() ReadLimits {
+	return ReadLimits{
+		MaxInputCount: 1 << 24,
+		MaxScriptSize: 1 << 32,
+		MaxSize:       1 << 34,
+	}
+}
+
+func (l ReadLimits) orDefault() ReadLimits {
+	defaults := DefaultReadLimits()
+	if l.MaxInputCount == 0 {
+		l.MaxInputCount = defaults.MaxInputCount
+	}
+	if l.MaxScriptSize == 0 {
+		l.MaxScriptSize = defaults.MaxScriptSize
+	}
+	if l.MaxSize == 0 {
+		l.MaxSize = defaults.MaxSize
+	}
+	return l
+}
+
+// decodeError wraps err in ErrDecodeFail. Limit errors are only wrapped with the message so
+// callers can still find them with errors.Cause.
+func decodeError(err error, message string) error {
+	switch errors.Cause(err) {
+	case ErrInputCountLimit, ErrScriptSizeLimit, ErrSizeLimit:
+		return errors.Wrap(err, message)
+	}
+
+	return errors.Wrap(ErrDecodeFail, errors.Wrap(err, message).Error())
+}
+
+// limitedReader returns ErrSizeLimit when more than the remaining bytes are requested. It never
+// reads past the limit from the underlying reader.
+type limitedReader struct {
+	r         io.Reader
+	remaining uint64
+}
+
+func newLimitedReader(r io.Reader, limit uint64) *limitedReader {
+	return &limitedReader{
+		r:         r,
+		remaining: limit,
+	}
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if lr.remaining == 0 {
+		return 0, ErrSizeLimit
+	}
+
+	if uint64(len(p)) > lr.remaining {
+		p = p[:lr.remaining]
+	}
+
+	n, err := lr.r.Read(p)
+	lr.remaining -= uint64(n)
+	return n, err
+}
+
+// readScript reads a script of the specified length without allocating more than the data
+// actually provided.
+func readScript(r io.Reader, length uint64, limits ReadLimits) ([]byte, error) {
+	if length > limits.MaxScriptSize {
+		return nil, errors.Wrapf(ErrScriptSizeLimit, "%d > %d", length, limits.MaxScriptSize)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := io.CopyN(buf, r, int64(length)); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readMsgTx reads a wire tx after verifying that its counts and scripts are within the limits and
+// that the data for them is actually present. wire.MsgTx.Deserialize allocates based on the counts
+// before the data is read.
+func readMsgTx(r io.Reader, limits ReadLimits) (*wire.MsgTx, error) {
+	buf := &bytes.Buffer{}
+	tr := io.TeeReader(r, buf)
+
+	// Version
+	if _, err := io.CopyN(io.Discard, tr, 4); err != nil {
+		return nil, errors.Wrap(err, "version")
+	}
+
+	inputCount, err := wire.ReadVarInt(tr, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "input count")
+	}
+	if inputCount > uint64(limits.MaxInputCount) {
+		return nil, errors.Wrapf(ErrInputCountLimit, "tx %d > %d", inputCount,
+			limits.MaxInputCount)
+	}
+
+	for i := uint64(0); i < inputCount; i++ {
+		// Outpoint
+		if _, err := io.CopyN(io.Discard, tr, 36); err != nil {
+			return nil, errors.Wrapf(err, "input %d outpoint", i)
+		}
+
+		if err := skipScript(tr, limits); err != nil {
+			return nil, errors.Wrapf(err, "input %d script", i)
+		}
+
+		// Sequence
+		if _, err := io.CopyN(io.Discard, tr, 4); err != nil {
+			return nil, errors.Wrapf(err, "input %d sequence", i)
+		}
+	}
+
+	outputCount, err := wire.ReadVarInt(tr, 0)
+	if err != nil {
+		return nil, errors.Wrap(err, "output count")
+	}
+
+	for i := uint64(0); i < outputCount; i++ {
+		// Value
+		if _, err := io.CopyN(io.Discard, tr, 8); err != nil {
+			return nil, errors.Wrapf(err, "output %d value", i)
+		}
+
+		if err := skipScript(tr, limits); err != nil {
+			return nil, errors.Wrapf(err, "output %d script", i)
+		}
+	}
+
+	// Lock time
+	if _, err := io.CopyN(io.Discard, tr, 4); err != nil {
+		return nil, errors.Wrap(err, "lock time")
+	}
+
+	msg := &wire.MsgTx{}
+	if err := msg.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func skipScript(r io.Reader, limits ReadLimits) error {
+	length, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return errors.Wrap(err, "length")
+	}
+
+	if length > limits.MaxScriptSize {
+		return errors.Wrapf(ErrScriptSizeLimit, "%d > %d", length, limits.MaxScriptSize)
+	}
+
+	if _, err := io.CopyN(io.Discard, r, int64(length)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// readInputCount reads the serialized input count and verifies it is within the limits.
+func readInputCount(r io.Reader, limits ReadLimits) (uint32, error) {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return 0, err
+	}
+
+	if count > limits.MaxInputCount {
+		return 0, errors.Wrapf(ErrInputCountLimit, "%d > %d", count, limits.MaxInputCount)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,151 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func Test_ReadLimits(t *testing.T) {
+	itx := newTestPromotedTx(t)
+
+	// Add second input so an input limit of 1 can be exceeded.
+	itx.MsgTx.AddTxIn(wire.NewTxIn(&itx.MsgTx.TxIn[0].PreviousOutPoint, nil))
+	itx.Inputs = append(itx.Inputs, &Input{Value: 1000,
+		LockingScript: itx.Inputs[0].LockingScript})
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write(buf); err != nil {
+		t.Fatalf("Failed to write tx : %s", err)
+	}
+	b := buf.Bytes()
+
+	tests := []struct {
+		name   string
+		limits ReadLimits
+		err    error
+	}{
+		{
+			name:   "input count",
+			limits: ReadLimits{MaxInputCount: 1},
+			err:    ErrInputCountLimit,
+		},
+		{
+			name:   "script size",
+			limits: ReadLimits{MaxScriptSize: 10},
+			err:    ErrScriptSizeLimit,
+		},
+		{
+			name:   "size",
+			limits: ReadLimits{MaxSize: uint64(len(b)) - 1},
+			err:    ErrSizeLimit,
+		},
+		{
+			name:   "exact size",
+			limits: ReadLimits{MaxSize: uint64(len(b))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultParseOptions(true)
+			opts.ReadLimits = tt.limits
+
+			err := (&Transaction{}).ReadWithOptions(bytes.NewReader(b), opts)
+			if errors.Cause(err) != tt.err {
+				t.Fatalf("Wrong error : got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_ReadLimits_Hostile(t *testing.T) {
+	// Claims a huge number of tx inputs with no data.
+	buf := &bytes.Buffer{}
+	buf.WriteByte(CurrentVersion)
+	binary.Write(buf, binary.LittleEndian, int32(1))
+	wire.WriteVarInt(buf, 0, 1<<23)
+
+	err := (&Transaction{}).Read(bytes.NewReader(buf.Bytes()), true)
+	if err == nil {
+		t.Fatalf("Hostile tx input count should fail")
+	}
+	t.Logf("Tx input count error : %s", err)
+
+	// Claims a huge locking script with no data.
+	buf = &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, uint64(1000))
+	wire.WriteVarInt(buf, 0, 1<<31)
+
+	input := &Input{}
+	if err := input.Read(3, bytes.NewReader(buf.Bytes())); err == nil {
+		t.Fatalf("Hostile script length should fail")
+	} else {
+		t.Logf("Script length error : %s", err)
+	}
+}
+
+func Test_ReadLimits_Decode(t *testing.T) {
+	ctx := context.Background()
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil))
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 2}, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, make([]byte, 100)))
+
+	buf := &bytes.Buffer{}
+	tx.Serialize(buf)
+	b := buf.Bytes()
+
+	tests := []struct {
+		name   string
+		limits ReadLimits
+		err    error
+	}{
+		{
+			name:   "input count",
+			limits: ReadLimits{MaxInputCount: 1},
+			err:    ErrInputCountLimit,
+		},
+		{
+			name:   "script size",
+			limits: ReadLimits{MaxScriptSize: 10},
+			err:    ErrScriptSizeLimit,
+		},
+		{
+			name:   "size",
+			limits: ReadLimits{MaxSize: uint64(len(b)) - 1},
+			err:    ErrSizeLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultParseOptions(true)
+			opts.ReadLimits = tt.limits
+
+			_, err := NewTransactionWithOptions(ctx, hex.EncodeToString(b), opts)
+			if errors.Cause(err) != tt.err {
+				t.Fatalf("Wrong hex error : got %v, want %v", err, tt.err)
+			}
+
+			decoder := NewTransactionDecoderWithOptions(bytes.NewReader(b), opts)
+			if _, err := decoder.Next(ctx); errors.Cause(err) != tt.err {
+				t.Fatalf("Wrong decoder error : got %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	// Other failures are still decode failures.
+	_, err := NewTransactionWithOptions(ctx, hex.EncodeToString(b[:len(b)-1]),
+		DefaultParseOptions(true))
+	if errors.Cause(err) != ErrDecodeFail {
+		t.Fatalf("Wrong truncated error : got %v, want %v", err, ErrDecodeFail)
+	}
+}
@@ -0,0 +1,349 @@
+package inspector
+
+import (
+	"crypto/sha256"
+	"io"
+	"sort"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/merkle_proof"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	merklePathFlagDuplicate = 0x01
+	merklePathFlagTxID      = 0x02
+
+	// maxMerklePathHeight is more than enough levels for any block.
+	maxMerklePathHeight = 64
+)
+
+var (
+	// ErrInvalidMerklePath means a merkle path doesn't contain the tx or the hashes needed to
+	// calculate its merkle root.
+	ErrInvalidMerklePath = errors.New("Invalid merkle path")
+)
+
+// MerklePath is a BSV Unified Merkle Path (BUMP) as defined by BRC-74. It contains the hashes
+// needed to calculate the merkle root of a block from one or more txs in the block.
+type MerklePath struct {
+	BlockHeight uint64
+
+	// Levels contains the leaves of each level of the merkle tree needed to calculate the root.
+	// Levels[0] contains the tx hashes.
+	Levels [][]MerklePathLeaf
+}
+
+// MerklePathLeaf is a hash in a merkle path.
+type MerklePathLeaf struct {
+	// Offset is the position of the hash in its level of the merkle tree.
+	Offset uint64
+
+	// Hash is zero when IsDuplicate is set.
+	Hash bitcoin.Hash32
+
+	// IsTxID means the hash is the id of a tx the merkle path is for.
+	IsTxID bool
+
+	// IsDuplicate means the hash is a duplicate of its sibling because it is the last hash of a
+	// level with an odd number of hashes.
+	IsDuplicate bool
+}
+
+// Contains returns true if the tx is in the first level of the merkle path.
+func (p MerklePath) Contains(txid bitcoin.Hash32) bool {
+	_, exists := p.txOffset(txid)
+	return exists
+}
+
+// MerkleProof returns a merkle proof for the tx that contains the merkle root calculated from the
+// path. The block hash and header are not set since the path only contains the block height.
+func (p MerklePath) MerkleProof(txid bitcoin.Hash32) (*merkle_proof.MerkleProof, error) {
+	offset, exists := p.txOffset(txid)
+	if !exists {
+		return nil, errors.Wrapf(ErrInvalidMerklePath, "missing tx %s", txid)
+	}
+
+	result := &merkle_proof.MerkleProof{
+		Index: int(offset),
+		TxID:  &txid,
+	}
+
+	for level := range p.Levels {
+		hash, isDuplicate, err := p.nodeHash(level, offset^1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "level %d", level)
+		}
+
+		if isDuplicate {
+			result.DuplicatedIndexes = append(result.DuplicatedIndexes, level+1)
+		} else {
+			result.Path = append(result.Path, hash)
+		}
+
+		offset /= 2
+	}
+
+	root, err := result.CalculateRoot()
+	if err != nil {
+		return nil, errors.Wrap(err, "calculate root")
+	}
+	result.MerkleRoot = &root
+
+	return result, nil
+}
+
+func (p MerklePath) txOffset(txid bitcoin.Hash32) (uint64, bool) {
+	if len(p.Levels) == 0 {
+		return 0, false
+	}
+
+	for _, leaf := range p.Levels[0] {
+		if !leaf.IsDuplicate && leaf.Hash.Equal(&txid) {
+			return leaf.Offset, true
+		}
+	}
+
+	return 0, false
+}
+
+// nodeHash returns the hash at the offset in the level. Hashes that aren't in the path are
+// calculated from the level below them.
+func (p MerklePath) nodeHash(level int, offset uint64) (bitcoin.Hash32, bool, error) {
+	for _, leaf := range p.Levels[level] {
+		if leaf.Offset == offset {
+			return leaf.Hash, leaf.IsDuplicate, nil
+		}
+	}
+
+	if level == 0 {
+		return bitcoin.Hash32{}, false, errors.Wrapf(ErrInvalidMerklePath,
+			"missing hash at level %d offset %d", level, offset)
+	}
+
+	left, isDuplicate, err := p.nodeHash(level-1, offset*2)
+	if err != nil {
+		return bitcoin.Hash32{}, false, err
+	}
+	if isDuplicate {
+		return bitcoin.Hash32{}, false, errors.Wrapf(ErrInvalidMerklePath,
+			"left duplicate at level %d offset %d", level-1, offset*2)
+	}
+
+	right, isDuplicate, err := p.nodeHash(level-1, offset*2+1)
+	if err != nil {
+		return bitcoin.Hash32{}, false, err
+	}
+	if isDuplicate {
+		right = left
+	}
+
+	return merkleParent(left, right), false, nil
+}
+
+// combine returns a merkle path containing the leaves of both paths. They must be for the same
+// block.
+func (p *MerklePath) combine(other *MerklePath) (*MerklePath, error) {
+	if p == other {
+		return p, nil
+	}
+
+	if p.BlockHeight != other.BlockHeight || len(p.Levels) != len(other.Levels) {
+		return nil, errors.Wrapf(ErrInvalidMerklePath, "different blocks : height %d, %d",
+			p.BlockHeight, other.BlockHeight)
+	}
+
+	root, err := p.root()
+	if err != nil {
+		return nil, errors.Wrap(err, "root")
+	}
+
+	otherRoot, err := other.root()
+	if err != nil {
+		return nil, errors.Wrap(err, "other root")
+	}
+
+	if !root.Equal(&otherRoot) {
+		return nil, errors.Wrapf(ErrInvalidMerklePath, "different roots : %s, %s", root,
+			otherRoot)
+	}
+
+	result := &MerklePath{
+		BlockHeight: p.BlockHeight,
+		Levels:      make([][]MerklePathLeaf, len(p.Levels)),
+	}
+
+	for l := range p.Levels {
+		leaves := make(map[uint64]MerklePathLeaf)
+		for _, level := range [][]MerklePathLeaf{p.Levels[l], other.Levels[l]} {
+			for _, leaf := range level {
+				if existing, exists := leaves[leaf.Offset]; exists {
+					leaf.IsTxID = leaf.IsTxID || existing.IsTxID
+				}
+				leaves[leaf.Offset] = leaf
+			}
+		}
+
+		for _, leaf := range leaves {
+			result.Levels[l] = append(result.Levels[l], leaf)
+		}
+		sort.Slice(result.Levels[l], func(i, j int) bool {
+			return result.Levels[l][i].Offset < result.Levels[l][j].Offset
+		})
+	}
+
+	return result, nil
+}
+
+// root returns the merkle root calculated from the first tx in the path.
+func (p MerklePath) root() (bitcoin.Hash32, error) {
+	if len(p.Levels) == 0 {
+		return bitcoin.Hash32{}, errors.Wrap(ErrInvalidMerklePath, "empty")
+	}
+
+	for _, leaf := range p.Levels[0] {
+		if leaf.IsDuplicate {
+			continue
+		}
+
+		proof, err := p.MerkleProof(leaf.Hash)
+		if err != nil {
+			return bitcoin.Hash32{}, err
+		}
+
+		return *proof.MerkleRoot, nil
+	}
+
+	return bitcoin.Hash32{}, errors.Wrap(ErrInvalidMerklePath, "no txs")
+}
+
+func merkleParent(left, right bitcoin.Hash32) bitcoin.Hash32 {
+	s := sha256.New()
+	s.Write(left[:])
+	s.Write(right[:])
+	return sha256.Sum256(s.Sum(nil))
+}
+
+// Serialize writes the merkle path in the BRC-74 binary format.
+func (p MerklePath) Serialize(w io.Writer) error {
+	if err := wire.WriteVarInt(w, 0, p.BlockHeight); err != nil {
+		return errors.Wrap(err, "block height")
+	}
+
+	if len(p.Levels) > maxMerklePathHeight {
+		return errors.Wrapf(ErrInvalidMerklePath, "tree height %d", len(p.Levels))
+	}
+
+	if _, err := w.Write([]byte{byte(len(p.Levels))}); err != nil {
+		return errors.Wrap(err, "tree height")
+	}
+
+	for l, level := range p.Levels {
+		if err := wire.WriteVarInt(w, 0, uint64(len(level))); err != nil {
+			return errors.Wrapf(err, "level %d count", l)
+		}
+
+		for i, leaf := range level {
+			if err := leaf.serialize(w); err != nil {
+				return errors.Wrapf(err, "level %d leaf %d", l, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l MerklePathLeaf) serialize(w io.Writer) error {
+	if err := wire.WriteVarInt(w, 0, l.Offset); err != nil {
+		return errors.Wrap(err, "offset")
+	}
+
+	var flags byte
+	if l.IsDuplicate {
+		flags = merklePathFlagDuplicate
+	} else if l.IsTxID {
+		flags = merklePathFlagTxID
+	}
+
+	if _, err := w.Write([]byte{flags}); err != nil {
+		return errors.Wrap(err, "flags")
+	}
+
+	if l.IsDuplicate {
+		return nil
+	}
+
+	if _, err := w.Write(l.Hash[:]); err != nil {
+		return errors.Wrap(err, "hash")
+	}
+
+	return nil
+}
+
+// Deserialize reads a merkle path in the BRC-74 binary format.
+func (p *MerklePath) Deserialize(r io.Reader) error {
+	blockHeight, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return errors.Wrap(err, "block height")
+	}
+	p.BlockHeight = blockHeight
+
+	var height [1]byte
+	if _, err := io.ReadFull(r, height[:]); err != nil {
+		return errors.Wrap(err, "tree height")
+	}
+	if height[0] > maxMerklePathHeight {
+		return errors.Wrapf(ErrInvalidMerklePath, "tree height %d", height[0])
+	}
+
+	p.Levels = make([][]MerklePathLeaf, height[0])
+	for l := range p.Levels {
+		count, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return errors.Wrapf(err, "level %d count", l)
+		}
+
+		// The count isn't used to allocate since it hasn't been verified by reading the leaves.
+		for i := uint64(0); i < count; i++ {
+			var leaf MerklePathLeaf
+			if err := leaf.deserialize(r); err != nil {
+				return errors.Wrapf(err, "level %d leaf %d", l, i)
+			}
+			p.Levels[l] = append(p.Levels[l], leaf)
+		}
+	}
+
+	return nil
+}
+
+func (l *MerklePathLeaf) deserialize(r io.Reader) error {
+	offset, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return errors.Wrap(err, "offset")
+	}
+	l.Offset = offset
+
+	var flags [1]byte
+	if _, err := io.ReadFull(r, flags[:]); err != nil {
+		return errors.Wrap(err, "flags")
+	}
+
+	switch flags[0] {
+	case 0:
+	case merklePathFlagDuplicate:
+		l.IsDuplicate = true
+		return nil
+	case merklePathFlagTxID:
+		l.IsTxID = true
+	default:
+		return errors.Wrapf(ErrInvalidMerklePath, "flags 0x%02x", flags[0])
+	}
+
+	if _, err := io.ReadFull(r, l.Hash[:]); err != nil {
+		return errors.Wrap(err, "hash")
+	}
+
+	return nil
+}
@@ -2,8 +2,10 @@ package inspector
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 
+	envelopeBase "github.com/tokenized/envelope/pkg/golang/envelope/base"
 	"github.com/tokenized/pkg/bitcoin"
 	"github.com/tokenized/pkg/wire"
 	"github.com/tokenized/specification/dist/golang/actions"
@@ -16,10 +18,76 @@ type Input struct {
 	LockingScript bitcoin.Script `json:"locking_script"`
 
 	Action actions.Action `json:"action"`
+
+	// ProtocolIDs are the envelope protocol IDs of the locking script when it is an envelope.
+	ProtocolIDs envelopeBase.ProtocolIDs `json:"protocol_ids,omitempty"`
+
+	// DecodeError is the reason a Tokenized envelope in the locking script failed to decode.
+	DecodeError error `json:"-"`
+
+	// Payloads contains the data found in the locking script by each matching decoder.
+	Payloads []*Payload `json:"payloads,omitempty"`
+
+	// ParentTx is the tx containing the output spent by the input when it is available.
+	ParentTx *wire.MsgTx `json:"-"`
+
+	// Verified is true when VerifyInputs has proven the parent tx is in a block and the value and
+	// locking script match it.
+	Verified bool `json:"verified,omitempty"`
+
+	// Unknown is true when partial promotion didn't have the output spent by the input, so Value
+	// and LockingScript aren't set.
+	Unknown bool `json:"unknown,omitempty"`
 }
 
 type Output struct {
 	Action actions.Action `json:"action"`
+
+	// ProtocolIDs are the envelope protocol IDs of the locking script when it is an envelope.
+	ProtocolIDs envelopeBase.ProtocolIDs `json:"protocol_ids,omitempty"`
+
+	// DecodeError is the reason a Tokenized envelope in the locking script failed to decode.
+	DecodeError error `json:"-"`
+
+	// Payloads contains the data found in the locking script by each matching decoder.
+	Payloads []*Payload `json:"payloads,omitempty"`
+}
+
+func (in *Input) setScriptData(data scriptData) {
+	in.Action = data.action
+	in.ProtocolIDs = data.protocolIDs
+	in.DecodeError = data.decodeErr
+	in.Payloads = data.payloads
+}
+
+func (out *Output) setScriptData(data scriptData) {
+	out.Action = data.action
+	out.ProtocolIDs = data.protocolIDs
+	out.DecodeError = data.decodeErr
+	out.Payloads = data.payloads
+}
+
+// resolveFromParent sets the value and locking script from the output of the parent tx that is
+// spent by the outpoint.
+func (in *Input) resolveFromParent(outpoint wire.OutPoint) error {
+	if in.ParentTx == nil {
+		return errors.New("Missing parent tx")
+	}
+
+	hash := in.ParentTx.TxHash()
+	if !hash.Equal(&outpoint.Hash) {
+		return fmt.Errorf("Wrong parent tx : got %s, want %s", hash, outpoint.Hash)
+	}
+
+	if int(outpoint.Index) >= len(in.ParentTx.TxOut) {
+		return fmt.Errorf("Parent tx output index out of range : %d >= %d", outpoint.Index,
+			len(in.ParentTx.TxOut))
+	}
+
+	output := in.ParentTx.TxOut[outpoint.Index]
+	in.Value = output.Value
+	in.LockingScript = output.LockingScript
+	return nil
 }
 
 // UTXOs is a wrapper for a []UTXO.
@@ -66,22 +134,50 @@ func (in Input) Write(w io.Writer) error {
 }
 
 func (in *Input) Read(version uint8, r io.Reader) error {
+	return in.ReadWithLimits(version, r, DefaultReadLimits())
+}
+
+// ReadWithLimits reads an input written with the specified version of the binary format, without
+// accepting scripts larger than the limits.
+func (in *Input) ReadWithLimits(version uint8, r io.Reader, limits ReadLimits) error {
+	limits = limits.orDefault()
+
 	switch version {
 	case 0:
-		// Read full tx
-		msg := wire.MsgTx{}
-		if err := msg.Deserialize(r); err != nil {
+		// Read full tx. Value and LockingScript are set by resolveFromParent since the spent
+		// output's index isn't included.
+		parentTx, err := readMsgTx(r, limits)
+		if err != nil {
 			return errors.Wrap(err, "read tx")
 		}
+		in.ParentTx = parentTx
 
 	case 1, 2:
-		utxo := bitcoin.UTXO{}
-		if err := utxo.Read(r); err != nil {
-			return errors.Wrap(err, "read utxo")
+		// bitcoin.UTXO format
+		var hash bitcoin.Hash32
+		if _, err := io.ReadFull(r, hash[:]); err != nil {
+			return errors.Wrap(err, "read utxo hash")
+		}
+
+		var scriptSize uint32
+		if err := binary.Read(r, binary.LittleEndian, &scriptSize); err != nil {
+			return errors.Wrap(err, "read utxo script length")
 		}
 
-		in.Value = utxo.Value
-		in.LockingScript = utxo.LockingScript
+		script, err := readScript(r, uint64(scriptSize), limits)
+		if err != nil {
+			return errors.Wrap(err, "read utxo script")
+		}
+		in.LockingScript = script
+
+		var index uint32
+		if err := binary.Read(r, binary.LittleEndian, &index); err != nil {
+			return errors.Wrap(err, "read utxo index")
+		}
+
+		if err := binary.Read(r, binary.LittleEndian, &in.Value); err != nil {
+			return errors.Wrap(err, "read utxo value")
+		}
 
 	default:
 		if err := binary.Read(r, binary.LittleEndian, &in.Value); err != nil {
@@ -93,10 +189,11 @@ func (in *Input) Read(version uint8, r io.Reader) error {
 			return errors.Wrap(err, "read script length")
 		}
 
-		in.LockingScript = make(bitcoin.Script, length)
-		if _, err := io.ReadFull(r, in.LockingScript); err != nil {
+		script, err := readScript(r, length, limits)
+		if err != nil {
 			return errors.Wrap(err, "read script")
 		}
+		in.LockingScript = script
 	}
 
 	return nil
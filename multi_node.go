@@ -0,0 +1,232 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// NodeSource is a named node used by a MultiNode.
+type NodeSource struct {
+	Name string
+	Node NodeInterface
+}
+
+// MultiNode is a NodeInterface that gets outputs from several nodes and only returns them when
+// enough of the nodes agree on the outpoint, value, and locking script.
+type MultiNode struct {
+	sources []NodeSource
+	quorum  int
+}
+
+// SourceOutput is the output, or error, returned by one source.
+type SourceOutput struct {
+	Source string
+	Output *bitcoin.UTXO
+	Err    error
+}
+
+// InconsistencyError is returned when not enough sources agree on an output, or when more than one
+// output has a quorum of sources.
+type InconsistencyError struct {
+	OutPoint wire.OutPoint
+	Quorum   int
+	Outputs  []SourceOutput
+}
+
+func (e *InconsistencyError) Error() string {
+	var results []string
+	for _, output := range e.Outputs {
+		if output.Err != nil {
+			results = append(results, fmt.Sprintf("%s: %s", output.Source, output.Err))
+		} else {
+			results = append(results, fmt.Sprintf("%s: %d %s", output.Source,
+				output.Output.Value, output.Output.LockingScript))
+		}
+	}
+
+	return fmt.Sprintf("Inconsistent output %s (quorum %d) : %s", e.OutPoint, e.Quorum,
+		strings.Join(results, ", "))
+}
+
+// NewMultiNode creates a MultiNode. quorum is the number of sources that must return the same
+// output. Zero means all sources must match. With a quorum of half the sources or less, different
+// outputs can each have a quorum and are treated as inconsistent.
+func NewMultiNode(quorum int, sources ...NodeSource) *MultiNode {
+	if quorum <= 0 || quorum > len(sources) {
+		quorum = len(sources)
+	}
+
+	return &MultiNode{
+		sources: sources,
+		quorum:  quorum,
+	}
+}
+
+// SaveTx saves the tx to all sources. It fails if fewer than a quorum of sources succeed.
+func (n *MultiNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	errs := make([]error, len(n.sources))
+	n.each(func(i int, source NodeSource) {
+		errs[i] = source.Node.SaveTx(ctx, tx)
+	})
+
+	succeeded := 0
+	var firstErr error
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if firstErr == nil {
+			firstErr = errors.Wrap(err, n.sources[i].Name)
+		}
+	}
+
+	if succeeded < n.quorum {
+		return firstErr
+	}
+
+	return nil
+}
+
+// GetTx returns the tx from the first source that returns it. The tx hash is verified, so it
+// doesn't need to be cross-checked.
+func (n *MultiNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	var firstErr error
+	for _, source := range n.sources {
+		tx, err := source.Node.GetTx(ctx, hash)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrap(err, source.Name)
+			}
+			continue
+		}
+
+		if !tx.TxHash().Equal(&hash) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: Wrong tx hash : got %s, want %s", source.Name,
+					tx.TxHash(), hash)
+			}
+			continue
+		}
+
+		return tx, nil
+	}
+
+	return nil, firstErr
+}
+
+// GetOutputs gets the outputs from all sources concurrently. It returns an *InconsistencyError for
+// the first output that fewer than a quorum of sources agree on, or that more than one quorum
+// disagrees on. If no source returns an output then the first source's error is returned.
+func (n *MultiNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	outputs := make([][]bitcoin.UTXO, len(n.sources))
+	errs := make([]error, len(n.sources))
+	n.each(func(i int, source NodeSource) {
+		sourceOutputs, err := source.Node.GetOutputs(ctx, outpoints)
+		if err == nil {
+			err = verifyOutputs(outpoints, sourceOutputs)
+		}
+
+		outputs[i] = sourceOutputs
+		errs[i] = err
+	})
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(n.sources) {
+		return nil, errors.Wrap(errs[0], n.sources[0].Name)
+	}
+
+	result := make([]bitcoin.UTXO, len(outpoints))
+	for o, outpoint := range outpoints {
+		sourceOutputs := make([]SourceOutput, len(n.sources))
+		for i, source := range n.sources {
+			sourceOutputs[i] = SourceOutput{
+				Source: source.Name,
+				Err:    errs[i],
+			}
+			if errs[i] == nil {
+				sourceOutputs[i].Output = &outputs[i][o]
+			}
+		}
+
+		output, agreed, runnerUp := agreedOutput(sourceOutputs)
+		if agreed < n.quorum || runnerUp >= n.quorum {
+			return nil, &InconsistencyError{
+				OutPoint: outpoint,
+				Quorum:   n.quorum,
+				Outputs:  sourceOutputs,
+			}
+		}
+
+		result[o] = *output
+	}
+
+	return result, nil
+}
+
+// each calls the function for each source concurrently and waits for them to finish.
+func (n *MultiNode) each(f func(i int, source NodeSource)) {
+	var wait sync.WaitGroup
+	for i, source := range n.sources {
+		wait.Add(1)
+		go func(i int, source NodeSource) {
+			defer wait.Done()
+			f(i, source)
+		}(i, source)
+	}
+	wait.Wait()
+}
+
+// agreedOutput returns the output returned by the most sources and how many returned it. It also
+// returns how many sources returned the next most common output.
+func agreedOutput(outputs []SourceOutput) (*bitcoin.UTXO, int, int) {
+	var result *bitcoin.UTXO
+	best, runnerUp := 0, 0
+	for i, output := range outputs {
+		if output.Output == nil {
+			continue
+		}
+
+		count := 0
+		counted := false
+		for j, other := range outputs {
+			if other.Output == nil || !outputsEqual(other.Output, output.Output) {
+				continue
+			}
+			if j < i {
+				counted = true // already counted with an earlier source
+				break
+			}
+			count++
+		}
+		if counted {
+			continue
+		}
+
+		if count > best {
+			result = output.Output
+			best, runnerUp = count, best
+		} else if count > runnerUp {
+			runnerUp = count
+		}
+	}
+
+	return result, best, runnerUp
+}
+
+func outputsEqual(l, r *bitcoin.UTXO) bool {
+	return l.Hash.Equal(&r.Hash) && l.Index == r.Index && l.Value == r.Value &&
+		l.LockingScript.Equal(r.LockingScript)
+}
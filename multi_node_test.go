@@ -0,0 +1,133 @@
+package inspector_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// lyingNode returns outputs with a different value.
+type lyingNode struct {
+	inspector.NodeInterface
+}
+
+func (n *lyingNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	outputs, err := n.NodeInterface.GetOutputs(ctx, outpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range outputs {
+		outputs[i].Value += 1000
+	}
+	return outputs, nil
+}
+
+func Test_MultiNode(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+	outpoints := []wire.OutPoint{{Hash: *parent.TxHash(), Index: 0}}
+
+	sources := []inspector.NodeSource{
+		{Name: "a", Node: node},
+		{Name: "b", Node: &lyingNode{NodeInterface: node}},
+		{Name: "c", Node: node},
+	}
+
+	outputs, err := inspector.NewMultiNode(2, sources...).GetOutputs(ctx, outpoints)
+	if err != nil {
+		t.Fatalf("Quorum should agree : %s", err)
+	}
+	if outputs[0].Value != parent.TxOut[0].Value {
+		t.Fatalf("Wrong value : got %d, want %d", outputs[0].Value, parent.TxOut[0].Value)
+	}
+
+	_, err = inspector.NewMultiNode(0, sources...).GetOutputs(ctx, outpoints)
+	inconsistent, ok := errors.Cause(err).(*inspector.InconsistencyError)
+	if !ok {
+		t.Fatalf("Wrong error : %v", err)
+	}
+	t.Logf("Inconsistency : %s", err)
+
+	if inconsistent.OutPoint != outpoints[0] || len(inconsistent.Outputs) != 3 ||
+		inconsistent.Outputs[1].Output.Value != parent.TxOut[0].Value+1000 {
+		t.Fatalf("Wrong inconsistency : %+v", inconsistent)
+	}
+
+	// A source that fails doesn't agree.
+	missing := inspectortest.NewNode()
+	sources[1] = inspector.NodeSource{Name: "b", Node: missing}
+	if _, err := inspector.NewMultiNode(2, sources...).GetOutputs(ctx, outpoints); err != nil {
+		t.Fatalf("Quorum should agree : %s", err)
+	}
+	if _, err := inspector.NewMultiNode(0, sources...).GetOutputs(ctx,
+		outpoints); errors.Cause(err).(*inspector.InconsistencyError) == nil {
+		t.Fatalf("Wrong error : %v", err)
+	}
+
+	// All sources fail.
+	failed := inspector.NodeSource{Name: "a", Node: missing}
+	_, err = inspector.NewMultiNode(0, failed).GetOutputs(ctx, outpoints)
+	if _, ok := errors.Cause(err).(*inspector.OutputNotFoundError); !ok {
+		t.Fatalf("Wrong error : %v", err)
+	}
+}
+
+func Test_MultiNode_LowQuorum(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	node := inspectortest.NewNode(parent)
+	outpoints := []wire.OutPoint{{Hash: *parent.TxHash(), Index: 0}}
+
+	// Two sources agree on each of two different outputs.
+	sources := []inspector.NodeSource{
+		{Name: "a", Node: node},
+		{Name: "b", Node: &lyingNode{NodeInterface: node}},
+		{Name: "c", Node: node},
+		{Name: "d", Node: &lyingNode{NodeInterface: node}},
+	}
+
+	for _, quorum := range []int{1, 2} {
+		_, err := inspector.NewMultiNode(quorum, sources...).GetOutputs(ctx, outpoints)
+		if _, ok := errors.Cause(err).(*inspector.InconsistencyError); !ok {
+			t.Fatalf("Wrong error for quorum %d : %v", quorum, err)
+		}
+	}
+
+	// An output returned for a different outpoint doesn't agree.
+	wrongOutPoint := &mutatingNode{
+		NodeInterface: node,
+		mutate: func(outputs []bitcoin.UTXO) []bitcoin.UTXO {
+			for i := range outputs {
+				outputs[i].Index++
+			}
+			return outputs
+		},
+	}
+
+	wrong := inspector.NodeSource{Name: "a", Node: wrongOutPoint}
+	_, err := inspector.NewMultiNode(1, wrong).GetOutputs(ctx, outpoints)
+	if err == nil {
+		t.Fatalf("Output for wrong outpoint should fail")
+	}
+
+	outputs, err := inspector.NewMultiNode(1, wrong,
+		inspector.NodeSource{Name: "b", Node: node}).GetOutputs(ctx, outpoints)
+	if err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+	if outputs[0].Index != outpoints[0].Index {
+		t.Fatalf("Wrong output index : got %d, want %d", outputs[0].Index, outpoints[0].Index)
+	}
+}
@@ -0,0 +1,89 @@
+package inspector_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+)
+
+func Test_Transaction_Promote_Unlocks(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newParentTx(t)
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxOut(wire.NewTxOut(900, parent.TxOut[0].LockingScript))
+
+	itx, err := inspector.NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	node := inspectortest.NewNode(parent)
+	utxos := []bitcoin.UTXO{{
+		Hash:          *parent.TxHash(),
+		Index:         0,
+		Value:         parent.TxOut[0].Value,
+		LockingScript: parent.TxOut[0].LockingScript,
+	}}
+
+	// Promoting used to return holding a read lock, so any later write blocked forever.
+	done := make(chan error, 1)
+	go func() {
+		if err := itx.PromoteFromUTXOs(ctx, utxos, true); err != nil {
+			done <- err
+			return
+		}
+		if err := itx.Promote(ctx, node, true); err != nil {
+			done <- err
+			return
+		}
+		done <- itx.PromoteFromUTXOs(ctx, utxos, true)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to promote : %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Promote didn't release the lock")
+	}
+}
+
+// newParentTx returns a tx with two outputs locked to a new key.
+func newParentTx(t *testing.T) *wire.MsgTx {
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+
+	tx, err := inspectortest.NewParentTx(key, 1000)
+	if err != nil {
+		t.Fatalf("Failed to create parent tx : %s", err)
+	}
+
+	return tx
+}
+
+// mutatingNode changes the outputs returned by a node to simulate a faulty backend.
+type mutatingNode struct {
+	inspector.NodeInterface
+	mutate func([]bitcoin.UTXO) []bitcoin.UTXO
+}
+
+func (n *mutatingNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	outputs, err := n.NodeInterface.GetOutputs(ctx, outpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.mutate(outputs), nil
+}
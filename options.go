@@ -0,0 +1,119 @@
+package inspector
+
+import (
+	envelopeBase "github.com/tokenized/envelope/pkg/golang/envelope/base"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+// ActionDecoder decodes a Tokenized action from a locking script. It must return
+// protocol.ErrNotTokenized when the script doesn't contain an action for the protocol ID selected
+// by isTest.
+type ActionDecoder func(script bitcoin.Script, isTest bool) (actions.Action, error)
+
+// ParseOptions specifies how the data in a transaction is found and decoded.
+//
+// The zero value is valid and is equivalent to DefaultParseOptions(false).
+type ParseOptions struct {
+	// Network is the bitcoin network the transaction belongs to. Zero means bitcoin.MainNet.
+	Network bitcoin.Network
+
+	// ProtocolIDs are the Tokenized protocol IDs that are accepted. Only protocol.ProtocolID and
+	// protocol.TestProtocolID are meaningful. Empty means protocol.ProtocolID.
+	ProtocolIDs []string
+
+	// Strict causes parsing to fail when a script is a Tokenized message, but can't be decoded.
+	// Otherwise such scripts are treated as if they didn't contain an action.
+	Strict bool
+
+	// ActionDecoders are applied in order by the TokenizedDecoder and the first to return an action
+	// is used. Empty means protocol.Deserialize.
+	ActionDecoders []ActionDecoder
+
+	// Decoders are applied to each locking script to find protocol data. Empty means the decoders
+	// added with RegisterDecoder.
+	Decoders []Decoder
+
+	// ReadLimits bounds the serialized data accepted by ReadWithOptions and
+	// NewTransactionWithOptions.
+	ReadLimits ReadLimits
+}
+
+// DefaultParseOptions returns the options equivalent to the isTest flag accepted by the original
+// functions. isTest selects the test protocol ID and does not change the network.
+func DefaultParseOptions(isTest bool) ParseOptions {
+	return ParseOptions{
+		Network:     bitcoin.MainNet,
+		ProtocolIDs: []string{string(protocol.GetProtocolID(isTest))},
+	}
+}
+
+// NetworkOrDefault returns the network, defaulting to main net.
+func (opts ParseOptions) NetworkOrDefault() bitcoin.Network {
+	if opts.Network == bitcoin.InvalidNet {
+		return bitcoin.MainNet
+	}
+	return opts.Network
+}
+
+// IsTest returns true if the test protocol ID is accepted.
+func (opts ParseOptions) IsTest() bool {
+	for _, id := range opts.ProtocolIDs {
+		if id == protocol.TestProtocolID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsProtocolID returns true if any of the protocol IDs are accepted Tokenized protocol IDs.
+func (opts ParseOptions) containsProtocolID(protocolIDs envelopeBase.ProtocolIDs) bool {
+	accepted := opts.ProtocolIDs
+	if len(accepted) == 0 {
+		accepted = []string{protocol.ProtocolID}
+	}
+
+	for _, protocolID := range protocolIDs {
+		for _, id := range accepted {
+			if string(protocolID) == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (opts ParseOptions) testFlags() []bool {
+	if len(opts.ProtocolIDs) == 0 {
+		return []bool{false}
+	}
+
+	var result []bool
+	for _, id := range opts.ProtocolIDs {
+		switch id {
+		case protocol.ProtocolID:
+			result = append(result, false)
+		case protocol.TestProtocolID:
+			result = append(result, true)
+		}
+	}
+
+	return result
+}
+
+func (opts ParseOptions) actionDecoders() []ActionDecoder {
+	if len(opts.ActionDecoders) == 0 {
+		return []ActionDecoder{protocol.Deserialize}
+	}
+	return opts.ActionDecoders
+}
+
+func (opts ParseOptions) decoders() []Decoder {
+	if len(opts.Decoders) == 0 {
+		return RegisteredDecoders()
+	}
+	return opts.Decoders
+}
@@ -0,0 +1,141 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	envelopeBase "github.com/tokenized/envelope/pkg/golang/envelope/base"
+	envelopeV1 "github.com/tokenized/envelope/pkg/golang/envelope/v1"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func Test_ParseOptions_ProtocolIDs(t *testing.T) {
+	ctx := context.Background()
+
+	script, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+	tx := newTestTx(t, script)
+
+	itx, err := NewTransactionFromWire(ctx, tx, false)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+	if itx.IsTokenized() {
+		t.Fatalf("Test protocol action should not be found with main protocol ID")
+	}
+
+	itx, err = NewTransactionFromWireWithOptions(ctx, tx, ParseOptions{
+		ProtocolIDs: []string{protocol.ProtocolID, protocol.TestProtocolID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+	if !itx.IsRequest() {
+		t.Fatalf("Test protocol action should be found when test protocol ID is allowed")
+	}
+}
+
+func Test_ParseOptions_Strict(t *testing.T) {
+	ctx := context.Background()
+
+	script := newMalformedActionScript(t, true)
+	tx := newTestTx(t, script)
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Lenient parse should not fail : %s", err)
+	}
+	if itx.IsTokenized() {
+		t.Fatalf("Malformed action should not be decoded")
+	}
+
+	opts := DefaultParseOptions(true)
+	opts.Strict = true
+	if _, err := NewTransactionFromWireWithOptions(ctx, tx, opts); err == nil {
+		t.Fatalf("Strict parse should fail")
+	} else {
+		t.Logf("Strict parse error : %s", err)
+	}
+
+	// Non-envelope OP_RETURN data is not an error in strict mode.
+	tx = newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 0x02, 0x01, 0x02})
+	if _, err := NewTransactionFromWireWithOptions(ctx, tx, opts); err != nil {
+		t.Fatalf("Strict parse of plain data should not fail : %s", err)
+	}
+}
+
+func Test_ParseOptions_ActionDecoders(t *testing.T) {
+	ctx := context.Background()
+
+	tx := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN})
+
+	called := 0
+	opts := ParseOptions{
+		ActionDecoders: []ActionDecoder{
+			func(script bitcoin.Script, isTest bool) (actions.Action, error) {
+				called++
+				if bytes.Equal(script, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN}) {
+					return &actions.ContractOffer{}, nil
+				}
+				return nil, protocol.ErrNotTokenized
+			},
+		},
+	}
+
+	itx, err := NewTransactionFromWireWithOptions(ctx, tx, opts)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if called != len(tx.TxOut) {
+		t.Fatalf("Wrong decoder call count : got %d, want %d", called, len(tx.TxOut))
+	}
+
+	if !itx.IsRequest() {
+		t.Fatalf("Custom decoder action should be found")
+	}
+}
+
+// newTestTx returns a tx with one input and a payment output followed by an output containing the
+// specified script.
+func newTestTx(t *testing.T, script bitcoin.Script) *wire.MsgTx {
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+
+	lockingScript, err := key.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	var previousHash bitcoin.Hash32
+	previousHash[0] = 1
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&previousHash, 0), nil))
+	tx.AddTxOut(wire.NewTxOut(1000, lockingScript))
+	tx.AddTxOut(wire.NewTxOut(0, script))
+	return tx
+}
+
+// newMalformedActionScript returns a Tokenized envelope with an action payload that can't be
+// decoded.
+func newMalformedActionScript(t *testing.T, isTest bool) bitcoin.Script {
+	message := envelopeV1.NewMessage(envelopeBase.ProtocolIDs{protocol.GetProtocolID(isTest)},
+		[][]byte{bitcoin.PushNumberScript(int64(protocol.Version)),
+			[]byte(actions.CodeContractOffer), []byte{0xff, 0xff, 0xff}})
+
+	var buf bytes.Buffer
+	if err := message.Serialize(&buf); err != nil {
+		t.Fatalf("Failed to serialize envelope : %s", err)
+	}
+
+	return bitcoin.Script(buf.Bytes())
+}
@@ -0,0 +1,218 @@
+package inspector
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tokenized/inspector/protobuf"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MarshalProto converts to protobuf. Payloads are not included since they are decoder specific.
+func (itx *Transaction) MarshalProto() ([]byte, error) {
+	pb, err := itx.ToProto()
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(pb)
+}
+
+// UnmarshalProto converts from protobuf.
+func (itx *Transaction) UnmarshalProto(data []byte) error {
+	pb := &protobuf.Transaction{}
+	if err := proto.Unmarshal(data, pb); err != nil {
+		return errors.Wrap(err, "protobuf unmarshal")
+	}
+
+	return itx.fromProto(pb)
+}
+
+// NewTransactionFromProto creates a transaction from its protobuf message. The actions are taken
+// from the message rather than decoded from the scripts so the result doesn't depend on parse
+// options.
+func NewTransactionFromProto(pb *protobuf.Transaction) (*Transaction, error) {
+	result := &Transaction{}
+	if err := result.fromProto(pb); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ToProto returns the protobuf message for the transaction.
+func (itx *Transaction) ToProto() (*protobuf.Transaction, error) {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	if itx.MsgTx == nil {
+		return nil, errors.New("Missing tx")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.MsgTx.Serialize(buf); err != nil {
+		return nil, errors.Wrap(err, "tx")
+	}
+
+	result := &protobuf.Transaction{
+		Hash:       itx.Hash.Bytes(),
+		Tx:         buf.Bytes(),
+		RejectCode: itx.RejectCode,
+		RejectText: itx.RejectText,
+		Network:    uint32(itx.Network),
+		IsTest:     itx.IsTest,
+	}
+
+	for i, input := range itx.Inputs {
+		action, err := newProtoAction(input.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "input %d", i)
+		}
+
+		result.Inputs = append(result.Inputs, &protobuf.Input{
+			Value:         input.Value,
+			LockingScript: input.LockingScript,
+			Action:        action,
+			DecodeError:   errorString(input.DecodeError),
+			Unknown:       input.Unknown,
+		})
+	}
+
+	for i, output := range itx.Outputs {
+		action, err := newProtoAction(output.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "output %d", i)
+		}
+
+		result.Outputs = append(result.Outputs, &protobuf.Output{
+			Action:      action,
+			DecodeError: errorString(output.DecodeError),
+		})
+	}
+
+	return result, nil
+}
+
+func (itx *Transaction) fromProto(pb *protobuf.Transaction) error {
+	if len(pb.Tx) == 0 {
+		return errors.New("Missing tx")
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(pb.Tx)); err != nil {
+		return errors.Wrap(err, "tx")
+	}
+
+	hash := tx.TxHash()
+	if len(pb.Hash) != 0 {
+		pbHash, err := bitcoin.NewHash32(pb.Hash)
+		if err != nil {
+			return errors.Wrap(err, "hash")
+		}
+
+		if !hash.Equal(pbHash) {
+			return fmt.Errorf("Wrong tx hash : got %s, want %s", pbHash, hash)
+		}
+	}
+
+	if len(pb.Outputs) != 0 && len(pb.Outputs) != len(tx.TxOut) {
+		return fmt.Errorf("Wrong output count : got %d, want %d", len(pb.Outputs), len(tx.TxOut))
+	}
+
+	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	itx.Hash = *hash
+	itx.MsgTx = tx
+	itx.RejectCode = pb.RejectCode
+	itx.RejectText = pb.RejectText
+	itx.Network = bitcoin.Network(pb.Network)
+	itx.IsTest = pb.IsTest
+
+	itx.Inputs = nil
+	for i, pbInput := range pb.Inputs {
+		action, err := protoAction(pbInput.Action)
+		if err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+
+		itx.Inputs = append(itx.Inputs, &Input{
+			Value:         pbInput.Value,
+			LockingScript: pbInput.LockingScript,
+			Action:        action,
+			ProtocolIDs:   envelopeProtocolIDs(pbInput.LockingScript),
+			DecodeError:   stringError(pbInput.DecodeError),
+			Unknown:       pbInput.Unknown,
+		})
+	}
+
+	itx.Outputs = nil
+	for i, pbOutput := range pb.Outputs {
+		action, err := protoAction(pbOutput.Action)
+		if err != nil {
+			return errors.Wrapf(err, "output %d", i)
+		}
+
+		itx.Outputs = append(itx.Outputs, &Output{
+			Action:      action,
+			ProtocolIDs: envelopeProtocolIDs(tx.TxOut[i].LockingScript),
+			DecodeError: stringError(pbOutput.DecodeError),
+		})
+	}
+
+	return nil
+}
+
+// newProtoAction embeds the action in the field of the Action oneof with the action's message
+// type.
+func newProtoAction(action actions.Action) (*protobuf.Action, error) {
+	if action == nil {
+		return nil, nil
+	}
+
+	message, ok := action.(protoreflect.ProtoMessage)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported action message : %s", action.Code())
+	}
+	value := message.ProtoReflect()
+
+	result := &protobuf.Action{}
+	reflected := result.ProtoReflect()
+	fields := reflected.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Message() != nil && field.Message().FullName() == value.Descriptor().FullName() {
+			reflected.Set(field, protoreflect.ValueOfMessage(value))
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unsupported action code : %s", action.Code())
+}
+
+// protoAction returns the action embedded in the Action oneof.
+func protoAction(pb *protobuf.Action) (actions.Action, error) {
+	if pb == nil {
+		return nil, nil
+	}
+
+	reflected := pb.ProtoReflect()
+	oneof := reflected.Descriptor().Oneofs().ByName("Action")
+	field := reflected.WhichOneof(oneof)
+	if field == nil {
+		return nil, errors.New("Missing action")
+	}
+
+	action, ok := reflected.Get(field).Message().Interface().(actions.Action)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported action message : %s", field.Message().FullName())
+	}
+
+	return action, nil
+}
@@ -0,0 +1,1102 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.0
+// 	protoc        v3.21.12
+// source: inspector.proto
+
+package protobuf
+
+import (
+	actions "github.com/tokenized/specification/dist/golang/actions"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Transaction is an inspected transaction with the outputs it spends and the Tokenized actions it
+// contains.
+type Transaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hash       []byte    `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"` // Hash of Tx
+	Tx         []byte    `protobuf:"bytes,2,opt,name=Tx,proto3" json:"Tx,omitempty"`     // Raw serialized tx
+	Inputs     []*Input  `protobuf:"bytes,3,rep,name=Inputs,proto3" json:"Inputs,omitempty"`
+	Outputs    []*Output `protobuf:"bytes,4,rep,name=Outputs,proto3" json:"Outputs,omitempty"`
+	RejectCode uint32    `protobuf:"varint,5,opt,name=RejectCode,proto3" json:"RejectCode,omitempty"`
+	RejectText string    `protobuf:"bytes,6,opt,name=RejectText,proto3" json:"RejectText,omitempty"`
+	Network    uint32    `protobuf:"varint,7,opt,name=Network,proto3" json:"Network,omitempty"` // bitcoin.Network
+	IsTest     bool      `protobuf:"varint,8,opt,name=IsTest,proto3" json:"IsTest,omitempty"`
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_inspector_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Transaction) GetTx() []byte {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+func (x *Transaction) GetInputs() []*Input {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *Transaction) GetOutputs() []*Output {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *Transaction) GetRejectCode() uint32 {
+	if x != nil {
+		return x.RejectCode
+	}
+	return 0
+}
+
+func (x *Transaction) GetRejectText() string {
+	if x != nil {
+		return x.RejectText
+	}
+	return ""
+}
+
+func (x *Transaction) GetNetwork() uint32 {
+	if x != nil {
+		return x.Network
+	}
+	return 0
+}
+
+func (x *Transaction) GetIsTest() bool {
+	if x != nil {
+		return x.IsTest
+	}
+	return false
+}
+
+// Input is the output spent by an input of the transaction.
+type Input struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value         uint64  `protobuf:"varint,1,opt,name=Value,proto3" json:"Value,omitempty"`
+	LockingScript []byte  `protobuf:"bytes,2,opt,name=LockingScript,proto3" json:"LockingScript,omitempty"`
+	Action        *Action `protobuf:"bytes,3,opt,name=Action,proto3" json:"Action,omitempty"`
+	DecodeError   string  `protobuf:"bytes,4,opt,name=DecodeError,proto3" json:"DecodeError,omitempty"`
+	Unknown       bool    `protobuf:"varint,5,opt,name=Unknown,proto3" json:"Unknown,omitempty"` // Spent output wasn't available
+}
+
+func (x *Input) Reset() {
+	*x = Input{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_inspector_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Input) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Input) ProtoMessage() {}
+
+func (x *Input) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Input.ProtoReflect.Descriptor instead.
+func (*Input) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Input) GetValue() uint64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Input) GetLockingScript() []byte {
+	if x != nil {
+		return x.LockingScript
+	}
+	return nil
+}
+
+func (x *Input) GetAction() *Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *Input) GetDecodeError() string {
+	if x != nil {
+		return x.DecodeError
+	}
+	return ""
+}
+
+func (x *Input) GetUnknown() bool {
+	if x != nil {
+		return x.Unknown
+	}
+	return false
+}
+
+// Output is the decoded data from an output of the transaction.
+type Output struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Action      *Action `protobuf:"bytes,1,opt,name=Action,proto3" json:"Action,omitempty"`
+	DecodeError string  `protobuf:"bytes,2,opt,name=DecodeError,proto3" json:"DecodeError,omitempty"`
+}
+
+func (x *Output) Reset() {
+	*x = Output{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_inspector_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Output) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Output) ProtoMessage() {}
+
+func (x *Output) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Output.ProtoReflect.Descriptor instead.
+func (*Output) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Output) GetAction() *Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *Output) GetDecodeError() string {
+	if x != nil {
+		return x.DecodeError
+	}
+	return ""
+}
+
+// Action is a Tokenized action embedded as its message from the specification.
+type Action struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Action:
+	//	*Action_ContractOffer
+	//	*Action_ContractFormation
+	//	*Action_ContractAmendment
+	//	*Action_StaticContractFormation
+	//	*Action_ContractAddressChange
+	//	*Action_BodyOfAgreementOffer
+	//	*Action_BodyOfAgreementFormation
+	//	*Action_BodyOfAgreementAmendment
+	//	*Action_InstrumentDefinition
+	//	*Action_InstrumentCreation
+	//	*Action_InstrumentModification
+	//	*Action_Transfer
+	//	*Action_Settlement
+	//	*Action_RectificationSettlement
+	//	*Action_Proposal
+	//	*Action_Vote
+	//	*Action_BallotCast
+	//	*Action_BallotCounted
+	//	*Action_Result
+	//	*Action_Order
+	//	*Action_Freeze
+	//	*Action_Thaw
+	//	*Action_Confiscation
+	//	*Action_DeprecatedReconciliation
+	//	*Action_Establishment
+	//	*Action_Addition
+	//	*Action_Alteration
+	//	*Action_Removal
+	//	*Action_Message
+	//	*Action_Rejection
+	Action isAction_Action `protobuf_oneof:"Action"`
+}
+
+func (x *Action) Reset() {
+	*x = Action{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_inspector_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Action) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Action) ProtoMessage() {}
+
+func (x *Action) ProtoReflect() protoreflect.Message {
+	mi := &file_inspector_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Action.ProtoReflect.Descriptor instead.
+func (*Action) Descriptor() ([]byte, []int) {
+	return file_inspector_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *Action) GetAction() isAction_Action {
+	if m != nil {
+		return m.Action
+	}
+	return nil
+}
+
+func (x *Action) GetContractOffer() *actions.ContractOffer {
+	if x, ok := x.GetAction().(*Action_ContractOffer); ok {
+		return x.ContractOffer
+	}
+	return nil
+}
+
+func (x *Action) GetContractFormation() *actions.ContractFormation {
+	if x, ok := x.GetAction().(*Action_ContractFormation); ok {
+		return x.ContractFormation
+	}
+	return nil
+}
+
+func (x *Action) GetContractAmendment() *actions.ContractAmendment {
+	if x, ok := x.GetAction().(*Action_ContractAmendment); ok {
+		return x.ContractAmendment
+	}
+	return nil
+}
+
+func (x *Action) GetStaticContractFormation() *actions.StaticContractFormation {
+	if x, ok := x.GetAction().(*Action_StaticContractFormation); ok {
+		return x.StaticContractFormation
+	}
+	return nil
+}
+
+func (x *Action) GetContractAddressChange() *actions.ContractAddressChange {
+	if x, ok := x.GetAction().(*Action_ContractAddressChange); ok {
+		return x.ContractAddressChange
+	}
+	return nil
+}
+
+func (x *Action) GetBodyOfAgreementOffer() *actions.BodyOfAgreementOffer {
+	if x, ok := x.GetAction().(*Action_BodyOfAgreementOffer); ok {
+		return x.BodyOfAgreementOffer
+	}
+	return nil
+}
+
+func (x *Action) GetBodyOfAgreementFormation() *actions.BodyOfAgreementFormation {
+	if x, ok := x.GetAction().(*Action_BodyOfAgreementFormation); ok {
+		return x.BodyOfAgreementFormation
+	}
+	return nil
+}
+
+func (x *Action) GetBodyOfAgreementAmendment() *actions.BodyOfAgreementAmendment {
+	if x, ok := x.GetAction().(*Action_BodyOfAgreementAmendment); ok {
+		return x.BodyOfAgreementAmendment
+	}
+	return nil
+}
+
+func (x *Action) GetInstrumentDefinition() *actions.InstrumentDefinition {
+	if x, ok := x.GetAction().(*Action_InstrumentDefinition); ok {
+		return x.InstrumentDefinition
+	}
+	return nil
+}
+
+func (x *Action) GetInstrumentCreation() *actions.InstrumentCreation {
+	if x, ok := x.GetAction().(*Action_InstrumentCreation); ok {
+		return x.InstrumentCreation
+	}
+	return nil
+}
+
+func (x *Action) GetInstrumentModification() *actions.InstrumentModification {
+	if x, ok := x.GetAction().(*Action_InstrumentModification); ok {
+		return x.InstrumentModification
+	}
+	return nil
+}
+
+func (x *Action) GetTransfer() *actions.Transfer {
+	if x, ok := x.GetAction().(*Action_Transfer); ok {
+		return x.Transfer
+	}
+	return nil
+}
+
+func (x *Action) GetSettlement() *actions.Settlement {
+	if x, ok := x.GetAction().(*Action_Settlement); ok {
+		return x.Settlement
+	}
+	return nil
+}
+
+func (x *Action) GetRectificationSettlement() *actions.RectificationSettlement {
+	if x, ok := x.GetAction().(*Action_RectificationSettlement); ok {
+		return x.RectificationSettlement
+	}
+	return nil
+}
+
+func (x *Action) GetProposal() *actions.Proposal {
+	if x, ok := x.GetAction().(*Action_Proposal); ok {
+		return x.Proposal
+	}
+	return nil
+}
+
+func (x *Action) GetVote() *actions.Vote {
+	if x, ok := x.GetAction().(*Action_Vote); ok {
+		return x.Vote
+	}
+	return nil
+}
+
+func (x *Action) GetBallotCast() *actions.BallotCast {
+	if x, ok := x.GetAction().(*Action_BallotCast); ok {
+		return x.BallotCast
+	}
+	return nil
+}
+
+func (x *Action) GetBallotCounted() *actions.BallotCounted {
+	if x, ok := x.GetAction().(*Action_BallotCounted); ok {
+		return x.BallotCounted
+	}
+	return nil
+}
+
+func (x *Action) GetResult() *actions.Result {
+	if x, ok := x.GetAction().(*Action_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Action) GetOrder() *actions.Order {
+	if x, ok := x.GetAction().(*Action_Order); ok {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *Action) GetFreeze() *actions.Freeze {
+	if x, ok := x.GetAction().(*Action_Freeze); ok {
+		return x.Freeze
+	}
+	return nil
+}
+
+func (x *Action) GetThaw() *actions.Thaw {
+	if x, ok := x.GetAction().(*Action_Thaw); ok {
+		return x.Thaw
+	}
+	return nil
+}
+
+func (x *Action) GetConfiscation() *actions.Confiscation {
+	if x, ok := x.GetAction().(*Action_Confiscation); ok {
+		return x.Confiscation
+	}
+	return nil
+}
+
+func (x *Action) GetDeprecatedReconciliation() *actions.DeprecatedReconciliation {
+	if x, ok := x.GetAction().(*Action_DeprecatedReconciliation); ok {
+		return x.DeprecatedReconciliation
+	}
+	return nil
+}
+
+func (x *Action) GetEstablishment() *actions.Establishment {
+	if x, ok := x.GetAction().(*Action_Establishment); ok {
+		return x.Establishment
+	}
+	return nil
+}
+
+func (x *Action) GetAddition() *actions.Addition {
+	if x, ok := x.GetAction().(*Action_Addition); ok {
+		return x.Addition
+	}
+	return nil
+}
+
+func (x *Action) GetAlteration() *actions.Alteration {
+	if x, ok := x.GetAction().(*Action_Alteration); ok {
+		return x.Alteration
+	}
+	return nil
+}
+
+func (x *Action) GetRemoval() *actions.Removal {
+	if x, ok := x.GetAction().(*Action_Removal); ok {
+		return x.Removal
+	}
+	return nil
+}
+
+func (x *Action) GetMessage() *actions.Message {
+	if x, ok := x.GetAction().(*Action_Message); ok {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *Action) GetRejection() *actions.Rejection {
+	if x, ok := x.GetAction().(*Action_Rejection); ok {
+		return x.Rejection
+	}
+	return nil
+}
+
+type isAction_Action interface {
+	isAction_Action()
+}
+
+type Action_ContractOffer struct {
+	ContractOffer *actions.ContractOffer `protobuf:"bytes,1,opt,name=ContractOffer,proto3,oneof"`
+}
+
+type Action_ContractFormation struct {
+	ContractFormation *actions.ContractFormation `protobuf:"bytes,2,opt,name=ContractFormation,proto3,oneof"`
+}
+
+type Action_ContractAmendment struct {
+	ContractAmendment *actions.ContractAmendment `protobuf:"bytes,3,opt,name=ContractAmendment,proto3,oneof"`
+}
+
+type Action_StaticContractFormation struct {
+	StaticContractFormation *actions.StaticContractFormation `protobuf:"bytes,4,opt,name=StaticContractFormation,proto3,oneof"`
+}
+
+type Action_ContractAddressChange struct {
+	ContractAddressChange *actions.ContractAddressChange `protobuf:"bytes,5,opt,name=ContractAddressChange,proto3,oneof"`
+}
+
+type Action_BodyOfAgreementOffer struct {
+	BodyOfAgreementOffer *actions.BodyOfAgreementOffer `protobuf:"bytes,6,opt,name=BodyOfAgreementOffer,proto3,oneof"`
+}
+
+type Action_BodyOfAgreementFormation struct {
+	BodyOfAgreementFormation *actions.BodyOfAgreementFormation `protobuf:"bytes,7,opt,name=BodyOfAgreementFormation,proto3,oneof"`
+}
+
+type Action_BodyOfAgreementAmendment struct {
+	BodyOfAgreementAmendment *actions.BodyOfAgreementAmendment `protobuf:"bytes,8,opt,name=BodyOfAgreementAmendment,proto3,oneof"`
+}
+
+type Action_InstrumentDefinition struct {
+	InstrumentDefinition *actions.InstrumentDefinition `protobuf:"bytes,9,opt,name=InstrumentDefinition,proto3,oneof"`
+}
+
+type Action_InstrumentCreation struct {
+	InstrumentCreation *actions.InstrumentCreation `protobuf:"bytes,10,opt,name=InstrumentCreation,proto3,oneof"`
+}
+
+type Action_InstrumentModification struct {
+	InstrumentModification *actions.InstrumentModification `protobuf:"bytes,11,opt,name=InstrumentModification,proto3,oneof"`
+}
+
+type Action_Transfer struct {
+	Transfer *actions.Transfer `protobuf:"bytes,12,opt,name=Transfer,proto3,oneof"`
+}
+
+type Action_Settlement struct {
+	Settlement *actions.Settlement `protobuf:"bytes,13,opt,name=Settlement,proto3,oneof"`
+}
+
+type Action_RectificationSettlement struct {
+	RectificationSettlement *actions.RectificationSettlement `protobuf:"bytes,14,opt,name=RectificationSettlement,proto3,oneof"`
+}
+
+type Action_Proposal struct {
+	Proposal *actions.Proposal `protobuf:"bytes,15,opt,name=Proposal,proto3,oneof"`
+}
+
+type Action_Vote struct {
+	Vote *actions.Vote `protobuf:"bytes,16,opt,name=Vote,proto3,oneof"`
+}
+
+type Action_BallotCast struct {
+	BallotCast *actions.BallotCast `protobuf:"bytes,17,opt,name=BallotCast,proto3,oneof"`
+}
+
+type Action_BallotCounted struct {
+	BallotCounted *actions.BallotCounted `protobuf:"bytes,18,opt,name=BallotCounted,proto3,oneof"`
+}
+
+type Action_Result struct {
+	Result *actions.Result `protobuf:"bytes,19,opt,name=Result,proto3,oneof"`
+}
+
+type Action_Order struct {
+	Order *actions.Order `protobuf:"bytes,20,opt,name=Order,proto3,oneof"`
+}
+
+type Action_Freeze struct {
+	Freeze *actions.Freeze `protobuf:"bytes,21,opt,name=Freeze,proto3,oneof"`
+}
+
+type Action_Thaw struct {
+	Thaw *actions.Thaw `protobuf:"bytes,22,opt,name=Thaw,proto3,oneof"`
+}
+
+type Action_Confiscation struct {
+	Confiscation *actions.Confiscation `protobuf:"bytes,23,opt,name=Confiscation,proto3,oneof"`
+}
+
+type Action_DeprecatedReconciliation struct {
+	DeprecatedReconciliation *actions.DeprecatedReconciliation `protobuf:"bytes,24,opt,name=DeprecatedReconciliation,proto3,oneof"`
+}
+
+type Action_Establishment struct {
+	Establishment *actions.Establishment `protobuf:"bytes,25,opt,name=Establishment,proto3,oneof"`
+}
+
+type Action_Addition struct {
+	Addition *actions.Addition `protobuf:"bytes,26,opt,name=Addition,proto3,oneof"`
+}
+
+type Action_Alteration struct {
+	Alteration *actions.Alteration `protobuf:"bytes,27,opt,name=Alteration,proto3,oneof"`
+}
+
+type Action_Removal struct {
+	Removal *actions.Removal `protobuf:"bytes,28,opt,name=Removal,proto3,oneof"`
+}
+
+type Action_Message struct {
+	Message *actions.Message `protobuf:"bytes,29,opt,name=Message,proto3,oneof"`
+}
+
+type Action_Rejection struct {
+	Rejection *actions.Rejection `protobuf:"bytes,30,opt,name=Rejection,proto3,oneof"`
+}
+
+func (*Action_ContractOffer) isAction_Action() {}
+
+func (*Action_ContractFormation) isAction_Action() {}
+
+func (*Action_ContractAmendment) isAction_Action() {}
+
+func (*Action_StaticContractFormation) isAction_Action() {}
+
+func (*Action_ContractAddressChange) isAction_Action() {}
+
+func (*Action_BodyOfAgreementOffer) isAction_Action() {}
+
+func (*Action_BodyOfAgreementFormation) isAction_Action() {}
+
+func (*Action_BodyOfAgreementAmendment) isAction_Action() {}
+
+func (*Action_InstrumentDefinition) isAction_Action() {}
+
+func (*Action_InstrumentCreation) isAction_Action() {}
+
+func (*Action_InstrumentModification) isAction_Action() {}
+
+func (*Action_Transfer) isAction_Action() {}
+
+func (*Action_Settlement) isAction_Action() {}
+
+func (*Action_RectificationSettlement) isAction_Action() {}
+
+func (*Action_Proposal) isAction_Action() {}
+
+func (*Action_Vote) isAction_Action() {}
+
+func (*Action_BallotCast) isAction_Action() {}
+
+func (*Action_BallotCounted) isAction_Action() {}
+
+func (*Action_Result) isAction_Action() {}
+
+func (*Action_Order) isAction_Action() {}
+
+func (*Action_Freeze) isAction_Action() {}
+
+func (*Action_Thaw) isAction_Action() {}
+
+func (*Action_Confiscation) isAction_Action() {}
+
+func (*Action_DeprecatedReconciliation) isAction_Action() {}
+
+func (*Action_Establishment) isAction_Action() {}
+
+func (*Action_Addition) isAction_Action() {}
+
+func (*Action_Alteration) isAction_Action() {}
+
+func (*Action_Removal) isAction_Action() {}
+
+func (*Action_Message) isAction_Action() {}
+
+func (*Action_Rejection) isAction_Action() {}
+
+var File_inspector_proto protoreflect.FileDescriptor
+
+var file_inspector_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x1a, 0x0d, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfa, 0x01, 0x0a, 0x0b,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x48,
+	0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x48, 0x61, 0x73, 0x68, 0x12,
+	0x0e, 0x0a, 0x02, 0x54, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x02, 0x54, 0x78, 0x12,
+	0x28, 0x0a, 0x06, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x49, 0x6e, 0x70, 0x75,
+	0x74, 0x52, 0x06, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x07, 0x4f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x69, 0x6e, 0x73,
+	0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x07, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x43, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x52, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x54, 0x65, 0x78, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x52, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x54, 0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x12, 0x16, 0x0a, 0x06, 0x49, 0x73, 0x54, 0x65, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x49, 0x73, 0x54, 0x65, 0x73, 0x74, 0x22, 0xaa, 0x01, 0x0a, 0x05, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x4c, 0x6f, 0x63, 0x6b,
+	0x69, 0x6e, 0x67, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0d, 0x4c, 0x6f, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x12, 0x29,
+	0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x44, 0x65, 0x63,
+	0x6f, 0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x55,
+	0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x55, 0x6e,
+	0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x22, 0x55, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12,
+	0x29, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x2e, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x44, 0x65,
+	0x63, 0x6f, 0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xc2, 0x0f, 0x0a,
+	0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3e, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x48, 0x00, 0x52, 0x0d, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x4a, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x43, 0x6f, 0x6e,
+	0x74, 0x72, 0x61, 0x63, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00,
+	0x52, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x4a, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41,
+	0x6d, 0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x41, 0x6d, 0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x11, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41, 0x6d, 0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x5c, 0x0a, 0x17, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x63, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x48, 0x00, 0x52, 0x17, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x43, 0x6f, 0x6e, 0x74,
+	0x72, 0x61, 0x63, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x56, 0x0a,
+	0x15, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41,
+	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x00, 0x52, 0x15,
+	0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x53, 0x0a, 0x14, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41,
+	0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x42, 0x6f,
+	0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4f, 0x66, 0x66,
+	0x65, 0x72, 0x48, 0x00, 0x52, 0x14, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x5f, 0x0a, 0x18, 0x42, 0x6f,
+	0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x46, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72,
+	0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48,
+	0x00, 0x52, 0x18, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5f, 0x0a, 0x18, 0x42,
+	0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x41, 0x6d,
+	0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67,
+	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x41, 0x6d, 0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74,
+	0x48, 0x00, 0x52, 0x18, 0x42, 0x6f, 0x64, 0x79, 0x4f, 0x66, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x41, 0x6d, 0x65, 0x6e, 0x64, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x53, 0x0a, 0x14,
+	0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x44,
+	0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x14, 0x49, 0x6e, 0x73,
+	0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x4d, 0x0a, 0x12, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65,
+	0x6e, 0x74, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x12, 0x49, 0x6e,
+	0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x59, 0x0a, 0x16, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x6f,
+	0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1f, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x49, 0x6e, 0x73, 0x74, 0x72,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x48, 0x00, 0x52, 0x16, 0x49, 0x6e, 0x73, 0x74, 0x72, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x4d,
+	0x6f, 0x64, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x08, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x08, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x35, 0x0a, 0x0a,
+	0x53, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x53, 0x65, 0x74, 0x74, 0x6c,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x0a, 0x53, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x5c, 0x0a, 0x17, 0x52, 0x65, 0x63, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x52,
+	0x65, 0x63, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74,
+	0x6c, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x17, 0x52, 0x65, 0x63, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x74, 0x74, 0x6c, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x2f, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x61, 0x6c, 0x12, 0x23, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x48,
+	0x00, 0x52, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x42, 0x61, 0x6c, 0x6c, 0x6f,
+	0x74, 0x43, 0x61, 0x73, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x43, 0x61, 0x73, 0x74,
+	0x48, 0x00, 0x52, 0x0a, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x43, 0x61, 0x73, 0x74, 0x12, 0x3e,
+	0x0a, 0x0d, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x18,
+	0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e,
+	0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52,
+	0x0d, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x12, 0x29,
+	0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x48,
+	0x00, 0x52, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x26, 0x0a, 0x05, 0x4f, 0x72, 0x64,
+	0x65, 0x72, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52, 0x05, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x12, 0x29, 0x0a, 0x06, 0x46, 0x72, 0x65, 0x65, 0x7a, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x46, 0x72, 0x65, 0x65,
+	0x7a, 0x65, 0x48, 0x00, 0x52, 0x06, 0x46, 0x72, 0x65, 0x65, 0x7a, 0x65, 0x12, 0x23, 0x0a, 0x04,
+	0x54, 0x68, 0x61, 0x77, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x54, 0x68, 0x61, 0x77, 0x48, 0x00, 0x52, 0x04, 0x54, 0x68, 0x61,
+	0x77, 0x12, 0x3b, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x73, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x17, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x73, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00,
+	0x52, 0x0c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x73, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x5f,
+	0x0a, 0x18, 0x44, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f,
+	0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x44, 0x65, 0x70, 0x72, 0x65,
+	0x63, 0x61, 0x74, 0x65, 0x64, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x18, 0x44, 0x65, 0x70, 0x72, 0x65, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x3e, 0x0a, 0x0d, 0x45, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x19, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x45, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x00,
+	0x52, 0x0d, 0x45, 0x73, 0x74, 0x61, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x2f, 0x0a, 0x08, 0x41, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x1a, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x41, 0x64, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x08, 0x41, 0x64, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x35, 0x0a, 0x0a, 0x41, 0x6c, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x1b,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x41,
+	0x6c, 0x74, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0a, 0x41, 0x6c, 0x74,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x07, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x61, 0x6c, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x07, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x61, 0x6c, 0x12, 0x2c, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x1d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x1e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x09, 0x52, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x08, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x69, 0x7a, 0x65, 0x64, 0x2f, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_inspector_proto_rawDescOnce sync.Once
+	file_inspector_proto_rawDescData = file_inspector_proto_rawDesc
+)
+
+func file_inspector_proto_rawDescGZIP() []byte {
+	file_inspector_proto_rawDescOnce.Do(func() {
+		file_inspector_proto_rawDescData = protoimpl.X.CompressGZIP(file_inspector_proto_rawDescData)
+	})
+	return file_inspector_proto_rawDescData
+}
+
+var file_inspector_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_inspector_proto_goTypes = []interface{}{
+	(*Transaction)(nil),                      // 0: inspector.Transaction
+	(*Input)(nil),                            // 1: inspector.Input
+	(*Output)(nil),                           // 2: inspector.Output
+	(*Action)(nil),                           // 3: inspector.Action
+	(*actions.ContractOffer)(nil),            // 4: actions.ContractOffer
+	(*actions.ContractFormation)(nil),        // 5: actions.ContractFormation
+	(*actions.ContractAmendment)(nil),        // 6: actions.ContractAmendment
+	(*actions.StaticContractFormation)(nil),  // 7: actions.StaticContractFormation
+	(*actions.ContractAddressChange)(nil),    // 8: actions.ContractAddressChange
+	(*actions.BodyOfAgreementOffer)(nil),     // 9: actions.BodyOfAgreementOffer
+	(*actions.BodyOfAgreementFormation)(nil), // 10: actions.BodyOfAgreementFormation
+	(*actions.BodyOfAgreementAmendment)(nil), // 11: actions.BodyOfAgreementAmendment
+	(*actions.InstrumentDefinition)(nil),     // 12: actions.InstrumentDefinition
+	(*actions.InstrumentCreation)(nil),       // 13: actions.InstrumentCreation
+	(*actions.InstrumentModification)(nil),   // 14: actions.InstrumentModification
+	(*actions.Transfer)(nil),                 // 15: actions.Transfer
+	(*actions.Settlement)(nil),               // 16: actions.Settlement
+	(*actions.RectificationSettlement)(nil),  // 17: actions.RectificationSettlement
+	(*actions.Proposal)(nil),                 // 18: actions.Proposal
+	(*actions.Vote)(nil),                     // 19: actions.Vote
+	(*actions.BallotCast)(nil),               // 20: actions.BallotCast
+	(*actions.BallotCounted)(nil),            // 21: actions.BallotCounted
+	(*actions.Result)(nil),                   // 22: actions.Result
+	(*actions.Order)(nil),                    // 23: actions.Order
+	(*actions.Freeze)(nil),                   // 24: actions.Freeze
+	(*actions.Thaw)(nil),                     // 25: actions.Thaw
+	(*actions.Confiscation)(nil),             // 26: actions.Confiscation
+	(*actions.DeprecatedReconciliation)(nil), // 27: actions.DeprecatedReconciliation
+	(*actions.Establishment)(nil),            // 28: actions.Establishment
+	(*actions.Addition)(nil),                 // 29: actions.Addition
+	(*actions.Alteration)(nil),               // 30: actions.Alteration
+	(*actions.Removal)(nil),                  // 31: actions.Removal
+	(*actions.Message)(nil),                  // 32: actions.Message
+	(*actions.Rejection)(nil),                // 33: actions.Rejection
+}
+var file_inspector_proto_depIdxs = []int32{
+	1,  // 0: inspector.Transaction.Inputs:type_name -> inspector.Input
+	2,  // 1: inspector.Transaction.Outputs:type_name -> inspector.Output
+	3,  // 2: inspector.Input.Action:type_name -> inspector.Action
+	3,  // 3: inspector.Output.Action:type_name -> inspector.Action
+	4,  // 4: inspector.Action.ContractOffer:type_name -> actions.ContractOffer
+	5,  // 5: inspector.Action.ContractFormation:type_name -> actions.ContractFormation
+	6,  // 6: inspector.Action.ContractAmendment:type_name -> actions.ContractAmendment
+	7,  // 7: inspector.Action.StaticContractFormation:type_name -> actions.StaticContractFormation
+	8,  // 8: inspector.Action.ContractAddressChange:type_name -> actions.ContractAddressChange
+	9,  // 9: inspector.Action.BodyOfAgreementOffer:type_name -> actions.BodyOfAgreementOffer
+	10, // 10: inspector.Action.BodyOfAgreementFormation:type_name -> actions.BodyOfAgreementFormation
+	11, // 11: inspector.Action.BodyOfAgreementAmendment:type_name -> actions.BodyOfAgreementAmendment
+	12, // 12: inspector.Action.InstrumentDefinition:type_name -> actions.InstrumentDefinition
+	13, // 13: inspector.Action.InstrumentCreation:type_name -> actions.InstrumentCreation
+	14, // 14: inspector.Action.InstrumentModification:type_name -> actions.InstrumentModification
+	15, // 15: inspector.Action.Transfer:type_name -> actions.Transfer
+	16, // 16: inspector.Action.Settlement:type_name -> actions.Settlement
+	17, // 17: inspector.Action.RectificationSettlement:type_name -> actions.RectificationSettlement
+	18, // 18: inspector.Action.Proposal:type_name -> actions.Proposal
+	19, // 19: inspector.Action.Vote:type_name -> actions.Vote
+	20, // 20: inspector.Action.BallotCast:type_name -> actions.BallotCast
+	21, // 21: inspector.Action.BallotCounted:type_name -> actions.BallotCounted
+	22, // 22: inspector.Action.Result:type_name -> actions.Result
+	23, // 23: inspector.Action.Order:type_name -> actions.Order
+	24, // 24: inspector.Action.Freeze:type_name -> actions.Freeze
+	25, // 25: inspector.Action.Thaw:type_name -> actions.Thaw
+	26, // 26: inspector.Action.Confiscation:type_name -> actions.Confiscation
+	27, // 27: inspector.Action.DeprecatedReconciliation:type_name -> actions.DeprecatedReconciliation
+	28, // 28: inspector.Action.Establishment:type_name -> actions.Establishment
+	29, // 29: inspector.Action.Addition:type_name -> actions.Addition
+	30, // 30: inspector.Action.Alteration:type_name -> actions.Alteration
+	31, // 31: inspector.Action.Removal:type_name -> actions.Removal
+	32, // 32: inspector.Action.Message:type_name -> actions.Message
+	33, // 33: inspector.Action.Rejection:type_name -> actions.Rejection
+	34, // [34:34] is the sub-list for method output_type
+	34, // [34:34] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
+}
+
+func init() { file_inspector_proto_init() }
+func file_inspector_proto_init() {
+	if File_inspector_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_inspector_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_inspector_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Input); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_inspector_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Output); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_inspector_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Action); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_inspector_proto_msgTypes[3].OneofWrappers = []interface{}{
+		(*Action_ContractOffer)(nil),
+		(*Action_ContractFormation)(nil),
+		(*Action_ContractAmendment)(nil),
+		(*Action_StaticContractFormation)(nil),
+		(*Action_ContractAddressChange)(nil),
+		(*Action_BodyOfAgreementOffer)(nil),
+		(*Action_BodyOfAgreementFormation)(nil),
+		(*Action_BodyOfAgreementAmendment)(nil),
+		(*Action_InstrumentDefinition)(nil),
+		(*Action_InstrumentCreation)(nil),
+		(*Action_InstrumentModification)(nil),
+		(*Action_Transfer)(nil),
+		(*Action_Settlement)(nil),
+		(*Action_RectificationSettlement)(nil),
+		(*Action_Proposal)(nil),
+		(*Action_Vote)(nil),
+		(*Action_BallotCast)(nil),
+		(*Action_BallotCounted)(nil),
+		(*Action_Result)(nil),
+		(*Action_Order)(nil),
+		(*Action_Freeze)(nil),
+		(*Action_Thaw)(nil),
+		(*Action_Confiscation)(nil),
+		(*Action_DeprecatedReconciliation)(nil),
+		(*Action_Establishment)(nil),
+		(*Action_Addition)(nil),
+		(*Action_Alteration)(nil),
+		(*Action_Removal)(nil),
+		(*Action_Message)(nil),
+		(*Action_Rejection)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_inspector_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_inspector_proto_goTypes,
+		DependencyIndexes: file_inspector_proto_depIdxs,
+		MessageInfos:      file_inspector_proto_msgTypes,
+	}.Build()
+	File_inspector_proto = out.File
+	file_inspector_proto_rawDesc = nil
+	file_inspector_proto_goTypes = nil
+	file_inspector_proto_depIdxs = nil
+}
@@ -0,0 +1,112 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func Test_Transaction_Proto(t *testing.T) {
+	ctx := context.Background()
+
+	offer := &actions.ContractOffer{
+		ContractName: "Test Contract",
+		ContractURI:  "https://tokenized.com",
+	}
+
+	script, err := protocol.Serialize(offer, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+	tx := newTestTx(t, script)
+	tx.AddTxOut(wire.NewTxOut(0, newMalformedActionScript(t, true)))
+
+	outputs := []*wire.TxOut{wire.NewTxOut(2000, tx.TxOut[0].LockingScript)}
+	itx, err := NewTransactionFromOutputs(ctx, *tx.TxHash(), tx, outputs, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+	itx.RejectCode = actions.RejectionsContractExists
+	itx.RejectText = "Contract exists"
+
+	b, err := itx.MarshalProto()
+	if err != nil {
+		t.Fatalf("Failed to marshal protobuf : %s", err)
+	}
+
+	readTx := &Transaction{}
+	if err := readTx.UnmarshalProto(b); err != nil {
+		t.Fatalf("Failed to unmarshal protobuf : %s", err)
+	}
+
+	if err := itx.Equal(readTx); err != nil {
+		t.Fatalf("Unmarshalled tx not equal : %s", err)
+	}
+
+	if readTx.RejectText != itx.RejectText {
+		t.Fatalf("Wrong reject text : got %s, want %s", readTx.RejectText, itx.RejectText)
+	}
+
+	if readTx.Network != itx.Network || readTx.IsTest != itx.IsTest {
+		t.Fatalf("Wrong parse metadata : got %s %t, want %s %t", readTx.Network, readTx.IsTest,
+			itx.Network, itx.IsTest)
+	}
+
+	readOffer, ok := readTx.Outputs[1].Action.(*actions.ContractOffer)
+	if !ok {
+		t.Fatalf("Wrong action type : %s", readTx.Outputs[1].Action.Code())
+	}
+	if !readOffer.Equal(offer) {
+		t.Fatalf("Wrong action : got %+v, want %+v", readOffer, offer)
+	}
+
+	if len(readTx.DecodeDiagnostics()) != 1 {
+		t.Fatalf("Decode diagnostics not retained")
+	}
+
+	pb, err := itx.ToProto()
+	if err != nil {
+		t.Fatalf("Failed to convert to protobuf : %s", err)
+	}
+	pb.Hash[0]++
+
+	if _, err := NewTransactionFromProto(pb); err == nil {
+		t.Fatalf("Wrong hash should fail")
+	}
+}
+
+func Test_Transaction_Proto_Unknown(t *testing.T) {
+	ctx := context.Background()
+
+	tx := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN})
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	if err := itx.PromotePartialFromUTXOs(ctx, nil, true); err != nil {
+		t.Fatalf("Failed to promote : %s", err)
+	}
+
+	b, err := itx.MarshalProto()
+	if err != nil {
+		t.Fatalf("Failed to marshal protobuf : %s", err)
+	}
+
+	readTx := &Transaction{}
+	if err := readTx.UnmarshalProto(b); err != nil {
+		t.Fatalf("Failed to unmarshal protobuf : %s", err)
+	}
+
+	if unknown := readTx.UnknownInputs(); len(unknown) != 1 || unknown[0] != 0 {
+		t.Fatalf("Wrong unknown inputs : got %v, want [0]", unknown)
+	}
+
+	if readTx.IsPromoted(ctx) {
+		t.Fatalf("Tx with unknown inputs should not be promoted")
+	}
+}
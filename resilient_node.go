@@ -0,0 +1,301 @@
+package inspector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrCircuitOpen means a ResilientNode is failing calls without trying them because the
+	// wrapped node has been failing.
+	ErrCircuitOpen = errors.New("Circuit open")
+)
+
+// CircuitState is the state of the circuit breaker of a ResilientNode.
+type CircuitState uint8
+
+const (
+	// CircuitClosed means calls are made normally.
+	CircuitClosed = CircuitState(0)
+
+	// CircuitOpen means calls fail with ErrCircuitOpen without being made.
+	CircuitOpen = CircuitState(1)
+
+	// CircuitHalfOpen means one trial call is being made to see if the node has recovered.
+	CircuitHalfOpen = CircuitState(2)
+)
+
+// ResilientConfig configures a ResilientNode.
+type ResilientConfig struct {
+	// Timeout is the maximum duration of each attempt. The deadline of the context passed to each
+	// call always applies, including to retries. Zero means only the context deadline applies.
+	Timeout time.Duration
+
+	// MaxRetries is the number of times a call is retried after a retryable error.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry. It doubles for each retry up to
+	// MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// FailureThreshold is the number of consecutive failed attempts that opens the circuit. Zero
+	// disables the circuit breaker.
+	FailureThreshold int
+
+	// ResetTimeout is how long the circuit stays open before a trial call is allowed.
+	ResetTimeout time.Duration
+
+	// IsRetryable returns true if the error is a node failure that should be retried. When nil,
+	// not found errors and errors returned by the node itself, like RPCError, are not retried.
+	IsRetryable func(error) bool
+}
+
+// DefaultResilientConfig returns the configuration used when none is specified.
+func DefaultResilientConfig() ResilientConfig {
+	return ResilientConfig{
+		Timeout:          30 * time.Second,
+		MaxRetries:       4,
+		InitialBackoff:   250 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		FailureThreshold: 10,
+		ResetTimeout:     30 * time.Second,
+	}
+}
+
+// ResilientStatus is the current state of a ResilientNode.
+type ResilientStatus struct {
+	State               CircuitState
+	ConsecutiveFailures int
+
+	// OpenedAt is when the circuit was last opened.
+	OpenedAt time.Time
+
+	Retries  uint64
+	Rejected uint64 // calls failed with ErrCircuitOpen
+}
+
+// ResilientNode wraps a NodeInterface with timeouts, retries with exponential backoff, and a
+// circuit breaker that fails calls quickly while the wrapped node is down.
+type ResilientNode struct {
+	node   NodeInterface
+	config ResilientConfig
+
+	status         ResilientStatus
+	trialInProcess bool
+
+	lock sync.Mutex
+}
+
+// NewResilientNode creates a ResilientNode.
+func NewResilientNode(node NodeInterface, config ResilientConfig) *ResilientNode {
+	if config.IsRetryable == nil {
+		config.IsRetryable = isRetryableNodeError
+	}
+
+	return &ResilientNode{
+		node:   node,
+		config: config,
+	}
+}
+
+// Status returns the current state so it can be reported by health checks.
+func (n *ResilientNode) Status() ResilientStatus {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.status
+}
+
+// State returns the current state of the circuit breaker.
+func (n *ResilientNode) State() CircuitState {
+	return n.Status().State
+}
+
+func (n *ResilientNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	return n.do(ctx, func(ctx context.Context) error {
+		return n.node.SaveTx(ctx, tx)
+	})
+}
+
+func (n *ResilientNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	var result *wire.MsgTx
+	err := n.do(ctx, func(ctx context.Context) error {
+		tx, err := n.node.GetTx(ctx, hash)
+		result = tx
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (n *ResilientNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	var result []bitcoin.UTXO
+	err := n.do(ctx, func(ctx context.Context) error {
+		outputs, err := n.node.GetOutputs(ctx, outpoints)
+		result = outputs
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// do makes the call, retrying retryable errors until it succeeds, the retries are used up, the
+// circuit opens, or the context is done. When the circuit opens the error is ErrCircuitOpen wrapped
+// with the last error from the node.
+func (n *ResilientNode) do(ctx context.Context, call func(ctx context.Context) error) error {
+	backoff := n.config.InitialBackoff
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := n.allow(); err != nil {
+			if lastErr != nil {
+				return errors.Wrap(err, lastErr.Error())
+			}
+			return err
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if n.config.Timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, n.config.Timeout)
+		}
+		err := call(attemptCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			// The caller gave up, so the call says nothing about the node.
+			n.released()
+			return err
+		}
+
+		if err == nil || !n.config.IsRetryable(err) {
+			// The node responded.
+			n.succeeded()
+			return err
+		}
+
+		lastErr = err
+
+		if opened := n.failed(); opened {
+			// Don't wait to retry when the retry would be rejected.
+			return errors.Wrapf(ErrCircuitOpen, "%d attempts: %s", attempt+1, err)
+		}
+
+		if attempt >= n.config.MaxRetries {
+			return errors.Wrapf(err, "%d attempts", attempt+1)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return errors.Wrap(err, ctx.Err().Error())
+		}
+
+		backoff *= 2
+		if n.config.MaxBackoff > 0 && backoff > n.config.MaxBackoff {
+			backoff = n.config.MaxBackoff
+		}
+
+		n.lock.Lock()
+		n.status.Retries++
+		n.lock.Unlock()
+	}
+}
+
+// allow returns ErrCircuitOpen if the call shouldn't be made.
+func (n *ResilientNode) allow() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	switch n.status.State {
+	case CircuitOpen:
+		if time.Since(n.status.OpenedAt) < n.config.ResetTimeout {
+			n.status.Rejected++
+			return ErrCircuitOpen
+		}
+
+		n.status.State = CircuitHalfOpen
+		n.trialInProcess = true
+
+	case CircuitHalfOpen:
+		if n.trialInProcess {
+			n.status.Rejected++
+			return ErrCircuitOpen
+		}
+		n.trialInProcess = true
+	}
+
+	return nil
+}
+
+func (n *ResilientNode) succeeded() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.status.State = CircuitClosed
+	n.status.ConsecutiveFailures = 0
+	n.trialInProcess = false
+}
+
+func (n *ResilientNode) released() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.trialInProcess = false
+}
+
+// failed records a failed attempt and returns true if the circuit is open.
+func (n *ResilientNode) failed() bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.status.ConsecutiveFailures++
+	n.trialInProcess = false
+
+	if n.config.FailureThreshold == 0 {
+		return false
+	}
+
+	if n.status.State == CircuitHalfOpen ||
+		n.status.ConsecutiveFailures >= n.config.FailureThreshold {
+		n.status.State = CircuitOpen
+		n.status.OpenedAt = time.Now()
+	}
+
+	return n.status.State == CircuitOpen
+}
+
+func isRetryableNodeError(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *OutputNotFoundError, *RPCError:
+		return false
+	}
+
+	return errors.Cause(err) != ErrTxNotFound
+}
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
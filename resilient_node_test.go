@@ -0,0 +1,194 @@
+package inspector_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/inspector"
+	"github.com/tokenized/inspector/inspectortest"
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// failingNode wraps a node and fails calls while failures remain, or blocks until the context is
+// done when block is set.
+type failingNode struct {
+	inspector.NodeInterface
+
+	failures int
+	block    bool
+	calls    int
+
+	lock sync.Mutex
+}
+
+var errTestNodeDown = errors.New("Node down")
+
+func (n *failingNode) fail(ctx context.Context) error {
+	n.lock.Lock()
+	n.calls++
+	block := n.block
+	failing := n.failures > 0
+	if failing {
+		n.failures--
+	}
+	n.lock.Unlock()
+
+	if block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if failing {
+		return errTestNodeDown
+	}
+
+	return nil
+}
+
+func (n *failingNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	if err := n.fail(ctx); err != nil {
+		return nil, err
+	}
+
+	return n.NodeInterface.GetTx(ctx, hash)
+}
+
+func Test_ResilientNode_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	tx := newParentTx(t)
+	node := inspectortest.NewNode(tx)
+
+	failing := &failingNode{NodeInterface: node, failures: 2}
+	resilient := inspector.NewResilientNode(failing, inspector.ResilientConfig{
+		MaxRetries:       3,
+		InitialBackoff:   time.Millisecond,
+		FailureThreshold: 5,
+		ResetTimeout:     time.Minute,
+	})
+
+	if _, err := resilient.GetTx(ctx, *tx.TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+
+	status := resilient.Status()
+	if failing.calls != 3 || status.Retries != 2 {
+		t.Fatalf("Wrong retries : got %d calls %d retries, want 3 calls 2 retries",
+			failing.calls, status.Retries)
+	}
+	if status.State != inspector.CircuitClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("Wrong status : %+v", status)
+	}
+
+	// Not found is not retried.
+	failing.calls = 0
+	if _, err := resilient.GetTx(ctx, bitcoin.Hash32{1}); err == nil {
+		t.Fatalf("Missing tx should fail")
+	}
+	if failing.calls != 1 {
+		t.Fatalf("Wrong calls for non-retryable error : got %d, want %d", failing.calls, 1)
+	}
+}
+
+func Test_ResilientNode_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	tx := newParentTx(t)
+	node := inspectortest.NewNode(tx)
+
+	failing := &failingNode{NodeInterface: node, failures: 100}
+	resilient := inspector.NewResilientNode(failing, inspector.ResilientConfig{
+		MaxRetries:       10,
+		InitialBackoff:   time.Millisecond,
+		FailureThreshold: 3,
+		ResetTimeout:     50 * time.Millisecond,
+	})
+
+	_, err := resilient.GetTx(ctx, *tx.TxHash())
+	if errors.Cause(err) != inspector.ErrCircuitOpen {
+		t.Fatalf("Wrong error : got %v, want %v", err, inspector.ErrCircuitOpen)
+	}
+	if !strings.Contains(err.Error(), errTestNodeDown.Error()) {
+		t.Fatalf("Error should contain the node error : %s", err)
+	}
+	if failing.calls != 3 || resilient.State() != inspector.CircuitOpen {
+		t.Fatalf("Circuit should open after 3 failures : %d calls, %s", failing.calls,
+			resilient.State())
+	}
+
+	// Fails fast while open.
+	_, err = resilient.GetTx(ctx, *tx.TxHash())
+	if errors.Cause(err) != inspector.ErrCircuitOpen {
+		t.Fatalf("Wrong error : got %v, want %v", err, inspector.ErrCircuitOpen)
+	}
+	if failing.calls != 3 {
+		t.Fatalf("Open circuit should not call node")
+	}
+
+	// Trial call after the reset timeout closes the circuit.
+	failing.failures = 0
+	time.Sleep(60 * time.Millisecond)
+	if _, err := resilient.GetTx(ctx, *tx.TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+
+	status := resilient.Status()
+	if status.State != inspector.CircuitClosed || status.Rejected != 1 {
+		t.Fatalf("Wrong status : %+v", status)
+	}
+	// Opening the circuit returns without waiting for the backoff.
+	failing.failures = 1
+	resilient = inspector.NewResilientNode(failing, inspector.ResilientConfig{
+		MaxRetries:       10,
+		InitialBackoff:   time.Hour,
+		FailureThreshold: 1,
+		ResetTimeout:     time.Minute,
+	})
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = resilient.GetTx(timeoutCtx, *tx.TxHash())
+	if errors.Cause(err) != inspector.ErrCircuitOpen {
+		t.Fatalf("Wrong error : got %v, want %v", err, inspector.ErrCircuitOpen)
+	}
+}
+
+func Test_ResilientNode_Timeout(t *testing.T) {
+	failing := &failingNode{NodeInterface: inspectortest.NewNode(), block: true}
+	resilient := inspector.NewResilientNode(failing, inspector.ResilientConfig{
+		Timeout:        10 * time.Millisecond,
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+	})
+
+	start := time.Now()
+	if _, err := resilient.GetTx(context.Background(), bitcoin.Hash32{}); err == nil {
+		t.Fatalf("Blocked call should time out")
+	}
+	if failing.calls != 2 {
+		t.Fatalf("Wrong calls : got %d, want %d", failing.calls, 2)
+	}
+
+	// Context deadline applies to retries.
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+
+	failing.calls = 0
+	resilient = inspector.NewResilientNode(failing, inspector.ResilientConfig{
+		Timeout:        10 * time.Millisecond,
+		MaxRetries:     100,
+		InitialBackoff: time.Millisecond,
+	})
+	if _, err := resilient.GetTx(ctx, bitcoin.Hash32{}); err == nil {
+		t.Fatalf("Blocked call should time out")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("Context deadline not applied")
+	}
+}
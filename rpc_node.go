@@ -0,0 +1,345 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/json"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRPCBatchSize is the maximum number of calls in one batch when not specified.
+	DefaultRPCBatchSize = 100
+
+	// bitcoind RPC error codes.
+	rpcInvalidAddressOrKey  = -5  // Tx not found
+	rpcVerifyAlreadyInChain = -27 // Tx already in the chain
+
+	// rpcResponseOverhead is the size allowed for the id, error, and json syntax of a response in
+	// addition to its hex encoded tx.
+	rpcResponseOverhead = 1 << 10
+)
+
+// RPCConfig configures an RPCNode.
+type RPCConfig struct {
+	// URL is the node's JSON-RPC endpoint. For example http://127.0.0.1:8332.
+	URL string `envconfig:"RPC_URL" json:"RPC_URL"`
+
+	// Username and Password are used for basic auth when Username is set.
+	Username string `envconfig:"RPC_USERNAME" json:"RPC_USERNAME"`
+	Password string `envconfig:"RPC_PASSWORD" json:"RPC_PASSWORD"`
+
+	// Authorization is the value of the Authorization header when set. For example a bearer
+	// token for a proxy in front of the node. It overrides basic auth.
+	Authorization string `envconfig:"RPC_AUTHORIZATION" json:"RPC_AUTHORIZATION"`
+
+	// BatchSize is the maximum number of calls in one batch request.
+	BatchSize int `default:"100" envconfig:"RPC_BATCH_SIZE" json:"RPC_BATCH_SIZE"`
+
+	// MaxTxSize is the maximum size of a tx returned by the node and bounds the size of the
+	// responses read. Zero means the MaxSize of DefaultReadLimits.
+	MaxTxSize uint64 `envconfig:"RPC_MAX_TX_SIZE" json:"RPC_MAX_TX_SIZE"`
+}
+
+// String returns a custom string representation.
+//
+// This is important so we don't log sensitive config values.
+func (c RPCConfig) String() string {
+	return fmt.Sprintf("{URL:%v Username:%v Password:%v Authorization:%v BatchSize:%d "+
+		"MaxTxSize:%d}", c.URL, c.Username, "****", "****", c.BatchSize, c.MaxTxSize)
+}
+
+// RPCNode is a NodeInterface that uses the JSON-RPC interface of a bitcoind compatible node, like
+// SV Node. The node must have txindex enabled to get txs that are no longer in the mempool.
+type RPCNode struct {
+	config RPCConfig
+	client *http.Client
+
+	nextID uint64
+}
+
+// RPCError is an error returned by the node.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d : %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// NewRPCNode creates an RPCNode. If client is nil then http.DefaultClient is used. Timeouts are
+// taken from the context of each call.
+func NewRPCNode(config RPCConfig, client *http.Client) *RPCNode {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultRPCBatchSize
+	}
+
+	if config.MaxTxSize == 0 {
+		config.MaxTxSize = DefaultReadLimits().MaxSize
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &RPCNode{
+		config: config,
+		client: client,
+	}
+}
+
+// SaveTx sends the tx to the node with sendrawtransaction. A tx that is already in the chain is
+// not an error.
+func (n *RPCNode) SaveTx(ctx context.Context, tx *wire.MsgTx) error {
+	buf := &bytes.Buffer{}
+	if err := tx.Serialize(buf); err != nil {
+		return errors.Wrap(err, "serialize")
+	}
+
+	responses, err := n.call(ctx, []*rpcRequest{
+		n.newRequest("sendrawtransaction", hex.EncodeToString(buf.Bytes())),
+	})
+	if err != nil {
+		return errors.Wrap(err, "sendrawtransaction")
+	}
+
+	if rpcErr := responses[0].Error; rpcErr != nil && rpcErr.Code != rpcVerifyAlreadyInChain {
+		return errors.Wrap(rpcErr, "sendrawtransaction")
+	}
+
+	return nil
+}
+
+// GetTx gets the tx from the node with getrawtransaction. It returns ErrTxNotFound if the node
+// doesn't have the tx.
+func (n *RPCNode) GetTx(ctx context.Context, hash bitcoin.Hash32) (*wire.MsgTx, error) {
+	txs, err := n.GetTxs(ctx, []bitcoin.Hash32{hash})
+	if err != nil {
+		return nil, err
+	}
+
+	if txs[0] == nil {
+		return nil, errors.Wrap(ErrTxNotFound, hash.String())
+	}
+
+	return txs[0], nil
+}
+
+// GetTxs gets the txs from the node with batched getrawtransaction calls. The tx for a hash the
+// node doesn't have is nil.
+func (n *RPCNode) GetTxs(ctx context.Context, hashes []bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	requests := make([]*rpcRequest, len(hashes))
+	for i, hash := range hashes {
+		requests[i] = n.newRequest("getrawtransaction", hash.String(), 0)
+	}
+
+	responses, err := n.call(ctx, requests)
+	if err != nil {
+		return nil, errors.Wrap(err, "getrawtransaction")
+	}
+
+	result := make([]*wire.MsgTx, len(hashes))
+	for i, response := range responses {
+		if response.Error != nil {
+			if response.Error.Code == rpcInvalidAddressOrKey {
+				continue // not found
+			}
+			return nil, errors.Wrapf(response.Error, "getrawtransaction %s", hashes[i])
+		}
+
+		tx, err := decodeRPCTx(response.Result)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode %s", hashes[i])
+		}
+
+		if !tx.TxHash().Equal(&hashes[i]) {
+			return nil, fmt.Errorf("Wrong tx hash : got %s, want %s", tx.TxHash(), hashes[i])
+		}
+
+		result[i] = tx
+	}
+
+	return result, nil
+}
+
+// GetOutputs gets the txs containing the outputs from the node in one batch. It returns an
+// *OutputNotFoundError if the node doesn't have the tx or it doesn't contain the output.
+func (n *RPCNode) GetOutputs(ctx context.Context,
+	outpoints []wire.OutPoint) ([]bitcoin.UTXO, error) {
+
+	var hashes []bitcoin.Hash32
+	indexes := make(map[bitcoin.Hash32]int)
+	for _, outpoint := range outpoints {
+		if _, exists := indexes[outpoint.Hash]; !exists {
+			indexes[outpoint.Hash] = len(hashes)
+			hashes = append(hashes, outpoint.Hash)
+		}
+	}
+
+	txs, err := n.GetTxs(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]bitcoin.UTXO, len(outpoints))
+	for i, outpoint := range outpoints {
+		tx := txs[indexes[outpoint.Hash]]
+		if tx == nil || int(outpoint.Index) >= len(tx.TxOut) {
+			return nil, &OutputNotFoundError{OutPoint: outpoint}
+		}
+
+		result[i] = bitcoin.UTXO{
+			Hash:          outpoint.Hash,
+			Index:         outpoint.Index,
+			Value:         tx.TxOut[outpoint.Index].Value,
+			LockingScript: tx.TxOut[outpoint.Index].LockingScript,
+		}
+	}
+
+	return result, nil
+}
+
+func (n *RPCNode) newRequest(method string, params ...interface{}) *rpcRequest {
+	return &rpcRequest{
+		JSONRPC: "1.0",
+		ID:      atomic.AddUint64(&n.nextID, 1),
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// call sends the requests in batches and returns the responses in the same order as the requests.
+// A single request is sent without a batch. Errors returned by the node are in the responses.
+func (n *RPCNode) call(ctx context.Context, requests []*rpcRequest) ([]*rpcResponse, error) {
+	result := make([]*rpcResponse, 0, len(requests))
+	for start := 0; start < len(requests); start += n.config.BatchSize {
+		end := start + n.config.BatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		responses, err := n.post(ctx, requests[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, responses...)
+	}
+
+	return result, nil
+}
+
+func (n *RPCNode) post(ctx context.Context, requests []*rpcRequest) ([]*rpcResponse, error) {
+	var body []byte
+	var err error
+	if len(requests) == 1 {
+		body, err = json.Marshal(requests[0])
+	} else {
+		body, err = json.Marshal(requests)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal")
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL,
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "request")
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	if len(n.config.Authorization) > 0 {
+		httpRequest.Header.Set("Authorization", n.config.Authorization)
+	} else if len(n.config.Username) > 0 {
+		httpRequest.SetBasicAuth(n.config.Username, n.config.Password)
+	}
+
+	httpResponse, err := n.client.Do(httpRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "http")
+	}
+	defer httpResponse.Body.Close()
+
+	// Read at most one byte more than allowed to detect responses that are too large.
+	maxSize := uint64(len(requests)) * (2*n.config.MaxTxSize + rpcResponseOverhead)
+	responseBody, err := io.ReadAll(io.LimitReader(httpResponse.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "read response")
+	}
+	if uint64(len(responseBody)) > maxSize {
+		return nil, errors.Wrapf(ErrSizeLimit, "response > %d", maxSize)
+	}
+
+	// bitcoind returns errors for single requests with an error status and a json body.
+	var responses []*rpcResponse
+	if len(requests) == 1 {
+		response := &rpcResponse{}
+		if err := json.Unmarshal(responseBody, response); err != nil {
+			return nil, fmt.Errorf("HTTP status %d : %s", httpResponse.StatusCode,
+				bytes.TrimSpace(responseBody))
+		}
+		responses = append(responses, response)
+	} else if err := json.Unmarshal(responseBody, &responses); err != nil {
+		return nil, fmt.Errorf("HTTP status %d : %s", httpResponse.StatusCode,
+			bytes.TrimSpace(responseBody))
+	}
+
+	// Responses to a batch can be in any order.
+	byID := make(map[uint64]*rpcResponse, len(responses))
+	for _, response := range responses {
+		byID[response.ID] = response
+	}
+
+	result := make([]*rpcResponse, len(requests))
+	for i, request := range requests {
+		response, exists := byID[request.ID]
+		if !exists {
+			return nil, fmt.Errorf("Missing response : %s %d", request.Method, request.ID)
+		}
+		result[i] = response
+	}
+
+	return result, nil
+}
+
+func decodeRPCTx(result json.RawMessage) (*wire.MsgTx, error) {
+	var raw string
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, errors.Wrap(err, "result")
+	}
+
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "hex")
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+		return nil, errors.Wrap(err, "deserialize")
+	}
+
+	return tx, nil
+}
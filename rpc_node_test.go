@@ -0,0 +1,195 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/json"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+// testRPCServer is a stand-in for the JSON-RPC interface of a node.
+type testRPCServer struct {
+	txs        map[bitcoin.Hash32]*wire.MsgTx
+	batchSizes []int
+
+	lock sync.Mutex
+}
+
+func (s *testRPCServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if username, password, ok := r.BasicAuth(); !ok || username != "user" ||
+		password != "pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var requests []*rpcRequest
+	if err := json.Unmarshal(body, &requests); err != nil {
+		request := &rpcRequest{}
+		if err := json.Unmarshal(body, request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.batchSizes = append(s.batchSizes, 1)
+		response := s.handle(request)
+		if response.Error != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	s.batchSizes = append(s.batchSizes, len(requests))
+
+	// Respond in reverse order to check responses are matched by id.
+	var responses []*rpcResponse
+	for i := len(requests) - 1; i >= 0; i-- {
+		responses = append(responses, s.handle(requests[i]))
+	}
+	json.NewEncoder(w).Encode(responses)
+}
+
+func (s *testRPCServer) handle(request *rpcRequest) *rpcResponse {
+	response := &rpcResponse{ID: request.ID}
+
+	switch request.Method {
+	case "getrawtransaction":
+		hash, _ := bitcoin.NewHash32FromStr(request.Params[0].(string))
+		tx, exists := s.txs[*hash]
+		if !exists {
+			response.Error = &RPCError{Code: rpcInvalidAddressOrKey,
+				Message: "No such mempool or blockchain transaction"}
+			return response
+		}
+
+		buf := &bytes.Buffer{}
+		tx.Serialize(buf)
+		response.Result, _ = json.Marshal(hex.EncodeToString(buf.Bytes()))
+
+	case "sendrawtransaction":
+		b, _ := hex.DecodeString(request.Params[0].(string))
+		tx := &wire.MsgTx{}
+		if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+			response.Error = &RPCError{Code: -22, Message: "TX decode failed"}
+			return response
+		}
+
+		s.txs[*tx.TxHash()] = tx
+		response.Result, _ = json.Marshal(tx.TxHash().String())
+
+	default:
+		response.Error = &RPCError{Code: -32601, Message: "Method not found"}
+	}
+
+	return response
+}
+
+func Test_RPCNode(t *testing.T) {
+	ctx := context.Background()
+
+	rpcServer := &testRPCServer{txs: make(map[bitcoin.Hash32]*wire.MsgTx)}
+	server := httptest.NewServer(rpcServer)
+	defer server.Close()
+
+	node := NewRPCNode(RPCConfig{
+		URL:       server.URL,
+		Username:  "user",
+		Password:  "pass",
+		BatchSize: 2,
+	}, server.Client())
+
+	var parents []*wire.MsgTx
+	for i := 0; i < 3; i++ {
+		parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, byte(i)})
+		if err := node.SaveTx(ctx, parent); err != nil {
+			t.Fatalf("Failed to save tx : %s", err)
+		}
+		parents = append(parents, parent)
+	}
+
+	tx, err := node.GetTx(ctx, *parents[1].TxHash())
+	if err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if !tx.TxHash().Equal(parents[1].TxHash()) {
+		t.Fatalf("Wrong tx hash : got %s, want %s", tx.TxHash(), parents[1].TxHash())
+	}
+
+	if _, err := node.GetTx(ctx, bitcoin.Hash32{1}); errors.Cause(err) != ErrTxNotFound {
+		t.Fatalf("Wrong missing tx error : got %v, want %v", err, ErrTxNotFound)
+	}
+
+	rpcServer.batchSizes = nil
+	outpoints := []wire.OutPoint{
+		{Hash: *parents[2].TxHash(), Index: 1},
+		{Hash: *parents[0].TxHash(), Index: 0},
+		{Hash: *parents[2].TxHash(), Index: 0},
+		{Hash: *parents[1].TxHash(), Index: 0},
+	}
+	outputs, err := node.GetOutputs(ctx, outpoints)
+	if err != nil {
+		t.Fatalf("Failed to get outputs : %s", err)
+	}
+
+	for i, output := range outputs {
+		if !output.Hash.Equal(&outpoints[i].Hash) || output.Index != outpoints[i].Index {
+			t.Fatalf("Wrong output %d : got %s:%d, want %s", i, output.Hash, output.Index,
+				outpoints[i])
+		}
+	}
+
+	// 3 unique txs in batches of 2.
+	if len(rpcServer.batchSizes) != 2 || rpcServer.batchSizes[0] != 2 ||
+		rpcServer.batchSizes[1] != 1 {
+		t.Fatalf("Wrong batches : got %v, want [2 1]", rpcServer.batchSizes)
+	}
+
+	missing := wire.OutPoint{Hash: bitcoin.Hash32{1}, Index: 3}
+	_, err = node.GetOutputs(ctx, []wire.OutPoint{outpoints[0], missing})
+	notFound, ok := errors.Cause(err).(*OutputNotFoundError)
+	if !ok || notFound.OutPoint != missing {
+		t.Fatalf("Wrong missing output error : %v", err)
+	}
+
+	// Responses larger than allowed for the max tx size aren't read.
+	large := newTestTx(t, append(bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN},
+		bitcoin.PushData(make([]byte, 2000))...))
+	if err := node.SaveTx(ctx, large); err != nil {
+		t.Fatalf("Failed to save tx : %s", err)
+	}
+
+	limited := NewRPCNode(RPCConfig{
+		URL:       server.URL,
+		Username:  "user",
+		Password:  "pass",
+		MaxTxSize: 1000,
+	}, server.Client())
+	if _, err := limited.GetTx(ctx, *large.TxHash()); errors.Cause(err) != ErrSizeLimit {
+		t.Fatalf("Wrong large response error : got %v, want %v", err, ErrSizeLimit)
+	}
+	if _, err := limited.GetTx(ctx, *parents[0].TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+
+	unauthorized := NewRPCNode(RPCConfig{URL: server.URL, Username: "user"}, server.Client())
+	if _, err := unauthorized.GetTx(ctx, *parents[0].TxHash()); err == nil {
+		t.Fatalf("Unauthorized request should fail")
+	} else {
+		t.Logf("Unauthorized error : %s", err)
+	}
+}
@@ -0,0 +1,171 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/merkle_proof"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrMissingMerkleProof means there is no merkle proof for the parent tx of an input.
+	ErrMissingMerkleProof = errors.New("Missing merkle proof")
+
+	// ErrMissingParentTx means the parent tx of an input isn't available to verify the input's
+	// value and locking script.
+	ErrMissingParentTx = errors.New("Missing parent tx")
+
+	// ErrParentMismatch means the output of the parent tx doesn't match the input.
+	ErrParentMismatch = errors.New("Parent tx doesn't match input")
+)
+
+// HeaderSource provides block headers to verify merkle proofs.
+type HeaderSource interface {
+	// GetHeader returns the header of the block with the specified hash. It must only return
+	// headers of blocks in the longest valid chain.
+	GetHeader(ctx context.Context, blockHash bitcoin.Hash32) (*wire.BlockHeader, error)
+}
+
+// VerifyInputs verifies that the parent tx of each input is in a block of the header source's
+// chain and that the input's value and locking script match the parent's output. proofs are TSC
+// merkle proofs keyed by parent tx hash. The parent tx is taken from the proof or from the input's
+// ParentTx. Inputs that are verified are marked Verified, and the first failure is returned.
+func (itx *Transaction) VerifyInputs(ctx context.Context, headers HeaderSource,
+	proofs map[bitcoin.Hash32]*merkle_proof.MerkleProof) error {
+
+	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	if len(itx.Inputs) != len(itx.MsgTx.TxIn) {
+		return ErrUnpromotedTx
+	}
+
+	blockHeaders := make(map[bitcoin.Hash32]*wire.BlockHeader)
+	var firstErr error
+	for i, txin := range itx.MsgTx.TxIn {
+		input := itx.Inputs[i]
+		input.Verified = false
+
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			continue // coinbase
+		}
+
+		if err := verifyInput(ctx, headers, proofs, blockHeaders, input,
+			txin.PreviousOutPoint); err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrapf(err, "input %d", i)
+			}
+			continue
+		}
+
+		input.Verified = true
+	}
+
+	return firstErr
+}
+
+// IsVerified returns true if all inputs have been verified with VerifyInputs.
+func (itx *Transaction) IsVerified() bool {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	if len(itx.Inputs) != len(itx.MsgTx.TxIn) {
+		return false
+	}
+
+	for i, txin := range itx.MsgTx.TxIn {
+		if txin.PreviousOutPoint.Index != 0xffffffff && !itx.Inputs[i].Verified {
+			return false
+		}
+	}
+
+	return true
+}
+
+func verifyInput(ctx context.Context, headers HeaderSource,
+	proofs map[bitcoin.Hash32]*merkle_proof.MerkleProof,
+	blockHeaders map[bitcoin.Hash32]*wire.BlockHeader, input *Input, outpoint wire.OutPoint) error {
+
+	if input.Unknown {
+		return ErrIncompleteTx
+	}
+
+	proof, exists := proofs[outpoint.Hash]
+	if !exists || proof == nil {
+		return errors.Wrap(ErrMissingMerkleProof, outpoint.Hash.String())
+	}
+
+	parentTx := proof.Tx
+	if parentTx == nil {
+		parentTx = input.ParentTx
+	}
+	if parentTx == nil {
+		return errors.Wrap(ErrMissingParentTx, outpoint.Hash.String())
+	}
+
+	if !parentTx.TxHash().Equal(&outpoint.Hash) {
+		return errors.Wrapf(ErrParentMismatch, "parent hash %s", parentTx.TxHash())
+	}
+
+	if int(outpoint.Index) >= len(parentTx.TxOut) {
+		return errors.Wrapf(ErrParentMismatch, "parent has %d outputs", len(parentTx.TxOut))
+	}
+
+	output := parentTx.TxOut[outpoint.Index]
+	if output.Value != input.Value || !output.LockingScript.Equal(input.LockingScript) {
+		return errors.Wrapf(ErrParentMismatch, "output %d", outpoint.Index)
+	}
+
+	if err := verifyMerkleProof(ctx, headers, proof, outpoint.Hash, blockHeaders); err != nil {
+		return errors.Wrap(err, "merkle proof")
+	}
+
+	input.ParentTx = parentTx
+	return nil
+}
+
+// verifyMerkleProof verifies that the proof links the tx to the merkle root of a block header
+// provided by the header source. blockHeaders caches the headers already retrieved.
+func verifyMerkleProof(ctx context.Context, headers HeaderSource,
+	proof *merkle_proof.MerkleProof, txid bitcoin.Hash32,
+	blockHeaders map[bitcoin.Hash32]*wire.BlockHeader) error {
+
+	// Calculate the root from the tx being verified rather than whatever the proof claims.
+	p := *proof
+	p.TxID = &txid
+	root, err := p.CalculateRoot()
+	if err != nil {
+		return errors.Wrap(err, "calculate root")
+	}
+
+	blockHash := proof.GetBlockHash()
+	if blockHash == nil {
+		return errors.Wrap(merkle_proof.ErrMissingTarget, "block hash or header required")
+	}
+
+	header, exists := blockHeaders[*blockHash]
+	if !exists {
+		var err error
+		header, err = headers.GetHeader(ctx, *blockHash)
+		if err != nil {
+			return errors.Wrapf(err, "header %s", blockHash)
+		}
+
+		if !header.BlockHash().Equal(blockHash) {
+			return fmt.Errorf("Wrong header hash : got %s, want %s", header.BlockHash(),
+				blockHash)
+		}
+
+		blockHeaders[*blockHash] = header
+	}
+
+	if !header.MerkleRoot.Equal(&root) {
+		return errors.Wrapf(merkle_proof.ErrWrongMerkleRoot, "block %s", blockHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package inspector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/merkle_proof"
+	"github.com/tokenized/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+type testHeaderSource map[bitcoin.Hash32]*wire.BlockHeader
+
+func (s testHeaderSource) GetHeader(ctx context.Context,
+	blockHash bitcoin.Hash32) (*wire.BlockHeader, error) {
+
+	header, exists := s[blockHash]
+	if !exists {
+		return nil, errors.New("Header not found")
+	}
+
+	return header, nil
+}
+
+func Test_VerifyInputs(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	tx.AddTxOut(wire.NewTxOut(900, parent.TxOut[0].LockingScript))
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	headers := make(testHeaderSource)
+	proofs := make(map[bitcoin.Hash32]*merkle_proof.MerkleProof)
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); errors.Cause(err) != ErrUnpromotedTx {
+		t.Fatalf("Wrong error for unpromoted tx : got %v, want %s", err, ErrUnpromotedTx)
+	}
+
+	if err := itx.PromoteFromUTXOs(ctx, parentUTXOs(parent), true); err != nil {
+		t.Fatalf("Failed to promote : %s", err)
+	}
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); errors.Cause(err) != ErrMissingMerkleProof {
+		t.Fatalf("Wrong error for missing proof : got %v, want %s", err, ErrMissingMerkleProof)
+	}
+
+	proof := merkle_proof.MockMerkleProofWithTx(parent, 7)
+	root, err := proof.CalculateRoot()
+	if err != nil {
+		t.Fatalf("Failed to calculate root : %s", err)
+	}
+
+	header := &wire.BlockHeader{
+		Version:    1,
+		MerkleRoot: root,
+		Timestamp:  uint32(time.Now().Unix()),
+	}
+	proof.BlockHash = nil
+	proof.BlockHeader = header
+	proofs[*parent.TxHash()] = proof
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); err == nil {
+		t.Fatalf("Verified with unknown block header")
+	}
+	if itx.IsVerified() {
+		t.Fatalf("Verified with unknown block header")
+	}
+
+	headers[*header.BlockHash()] = header
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); err != nil {
+		t.Fatalf("Failed to verify inputs : %s", err)
+	}
+	if !itx.IsVerified() {
+		t.Fatalf("Not verified")
+	}
+
+	// A header with a different merkle root doesn't verify the proof.
+	otherHeader := *header
+	otherHeader.MerkleRoot[0] ^= 0xff
+	headers[*otherHeader.BlockHash()] = &otherHeader
+	proof.BlockHeader = nil
+	proof.BlockHash = otherHeader.BlockHash()
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); errors.Cause(err) !=
+		merkle_proof.ErrWrongMerkleRoot {
+		t.Fatalf("Wrong error for wrong merkle root : got %v, want %s", err,
+			merkle_proof.ErrWrongMerkleRoot)
+	}
+	if itx.IsVerified() {
+		t.Fatalf("Verified with wrong merkle root")
+	}
+
+	// The input must match the parent tx in the proof.
+	proof.BlockHash = header.BlockHash()
+	itx.Inputs[0].Value++
+
+	if err := itx.VerifyInputs(ctx, headers, proofs); errors.Cause(err) != ErrParentMismatch {
+		t.Fatalf("Wrong error for wrong value : got %v, want %s", err, ErrParentMismatch)
+	}
+	if itx.Inputs[0].Verified || !itx.Inputs[1].Verified {
+		t.Fatalf("Wrong verified inputs : got %t %t, want false true", itx.Inputs[0].Verified,
+			itx.Inputs[1].Verified)
+	}
+}
@@ -1,7 +1,9 @@
 package inspector
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -12,11 +14,20 @@ import (
 	"github.com/tokenized/pkg/json"
 	"github.com/tokenized/pkg/wire"
 	"github.com/tokenized/specification/dist/golang/actions"
-	"github.com/tokenized/specification/dist/golang/protocol"
 
 	"github.com/pkg/errors"
 )
 
+const (
+	// CurrentVersion is the version of the binary format written by Write. Read accepts this and all
+	// previous versions.
+	CurrentVersion = uint8(4)
+
+	// checksumSize is the number of bytes of the double SHA256 of the data that are written as a
+	// checksum.
+	checksumSize = 4
+)
+
 var (
 	// Protocol Request message types
 	requestMessageTypes = map[string]bool{
@@ -63,6 +74,10 @@ type Transaction struct {
 	RejectCode uint32
 	RejectText string
 
+	// Network and IsTest are the network and test protocol flag the data was first parsed with.
+	Network bitcoin.Network
+	IsTest  bool
+
 	lock sync.RWMutex
 }
 
@@ -125,18 +140,26 @@ func (itx *Transaction) String(net bitcoin.Network) string {
 
 // Setup finds the tokenized messages.
 func (itx *Transaction) Setup(ctx context.Context, isTest bool) error {
+	return itx.SetupWithOptions(ctx, DefaultParseOptions(isTest))
+}
+
+// SetupWithOptions finds the tokenized messages as specified by the options.
+func (itx *Transaction) SetupWithOptions(ctx context.Context, opts ParseOptions) error {
 	itx.lock.Lock()
 	defer itx.lock.Unlock()
 
+	itx.Network = opts.NetworkOrDefault()
+	itx.IsTest = opts.IsTest()
+
 	for i, input := range itx.Inputs {
-		action, err := protocol.Deserialize(input.LockingScript, isTest)
-		if err == nil {
-			itx.Inputs[i].Action = action
+		data, err := decodeScript(input.LockingScript, opts)
+		if err != nil && opts.Strict {
+			return errors.Wrapf(err, "input %d", i)
 		}
+		itx.Inputs[i].setScriptData(data)
 	}
 
-	if err := itx.ParseOutputs(isTest); err != nil {
-		itx.lock.Unlock()
+	if err := itx.ParseOutputsWithOptions(opts); err != nil {
 		return errors.Wrap(err, "parse outputs")
 	}
 
@@ -180,53 +203,222 @@ func (itx *Transaction) Validate(ctx context.Context) error {
 // PromoteFromUTXOs will populate the inputs and outputs accordingly using UTXOs instead of a node.
 func (itx *Transaction) PromoteFromUTXOs(ctx context.Context, utxos []bitcoin.UTXO,
 	isTest bool) error {
+	return itx.PromoteFromUTXOsWithOptions(ctx, utxos, DefaultParseOptions(isTest))
+}
+
+// PromoteFromUTXOsWithOptions will populate the inputs and outputs accordingly using UTXOs instead
+// of a node.
+func (itx *Transaction) PromoteFromUTXOsWithOptions(ctx context.Context, utxos []bitcoin.UTXO,
+	opts ParseOptions) error {
 	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	itx.Network = opts.NetworkOrDefault()
+	itx.IsTest = opts.IsTest()
 
-	if err := itx.ParseInputsFromUTXOs(ctx, utxos, isTest); err != nil {
-		itx.lock.Unlock()
+	if err := itx.ParseInputsFromUTXOsWithOptions(ctx, utxos, opts); err != nil {
 		return errors.Wrap(err, "parse inputs")
 	}
 
-	if err := itx.ParseOutputs(isTest); err != nil {
-		itx.lock.Unlock()
+	if err := itx.ParseOutputsWithOptions(opts); err != nil {
 		return errors.Wrap(err, "parse outputs")
 	}
 
-	itx.lock.Unlock()
-	itx.lock.RLock()
 	return nil
 }
 
 // Promote will populate the inputs and outputs accordingly
 func (itx *Transaction) Promote(ctx context.Context, node NodeInterface, isTest bool) error {
+	return itx.PromoteWithOptions(ctx, node, DefaultParseOptions(isTest))
+}
+
+// PromoteWithOptions will populate the inputs and outputs accordingly
+func (itx *Transaction) PromoteWithOptions(ctx context.Context, node NodeInterface,
+	opts ParseOptions) error {
 	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	itx.Network = opts.NetworkOrDefault()
+	itx.IsTest = opts.IsTest()
 
-	if err := itx.ParseInputs(ctx, node, isTest); err != nil {
-		itx.lock.Unlock()
+	if err := itx.ParseInputsWithOptions(ctx, node, opts); err != nil {
 		return errors.Wrap(err, "parse inputs")
 	}
 
-	if err := itx.ParseOutputs(isTest); err != nil {
-		itx.lock.Unlock()
+	if err := itx.ParseOutputsWithOptions(opts); err != nil {
 		return errors.Wrap(err, "parse outputs")
 	}
 
-	itx.lock.Unlock()
-	itx.lock.RLock()
 	return nil
 }
 
-// IsPromoted returns true if inputs and outputs are populated.
+// PromotePartialFromUTXOs populates the inputs and outputs like PromoteFromUTXOs, but the UTXOs
+// can be in any order and inputs spending outputs that aren't in them are marked Unknown instead
+// of failing.
+func (itx *Transaction) PromotePartialFromUTXOs(ctx context.Context, utxos []bitcoin.UTXO,
+	isTest bool) error {
+	return itx.PromotePartialFromUTXOsWithOptions(ctx, utxos, DefaultParseOptions(isTest))
+}
+
+// PromotePartialFromUTXOsWithOptions populates the inputs and outputs, marking inputs spending
+// outputs that aren't in the UTXOs as Unknown.
+func (itx *Transaction) PromotePartialFromUTXOsWithOptions(ctx context.Context,
+	utxos []bitcoin.UTXO, opts ParseOptions) error {
+	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	byOutPoint := make(map[wire.OutPoint]bitcoin.UTXO, len(utxos))
+	for _, utxo := range utxos {
+		byOutPoint[wire.OutPoint{Hash: utxo.Hash, Index: utxo.Index}] = utxo
+	}
+
+	return itx.promotePartial(byOutPoint, opts)
+}
+
+// PromoteFromParents populates the inputs and outputs from the parent txs, keyed by tx hash, that
+// contain the outputs spent by the inputs. The parents are kept in the inputs' ParentTx. Inputs
+// whose parent isn't provided are marked Unknown and a *MissingParentsError listing the missing
+// parents is returned. It fails with ErrParentMismatch if a parent's hash doesn't match its key.
+func (itx *Transaction) PromoteFromParents(ctx context.Context,
+	parents map[bitcoin.Hash32]*wire.MsgTx, opts ParseOptions) error {
+	itx.lock.Lock()
+	defer itx.lock.Unlock()
+
+	byOutPoint := make(map[wire.OutPoint]bitcoin.UTXO, len(itx.MsgTx.TxIn))
+	missing := &MissingParentsError{}
+	added := make(map[bitcoin.Hash32]bool)
+	checked := make(map[bitcoin.Hash32]bool)
+	for i, txin := range itx.MsgTx.TxIn {
+		outpoint := txin.PreviousOutPoint
+		if outpoint.Index == 0xffffffff {
+			continue // skip coinbase inputs
+		}
+
+		parent, exists := parents[outpoint.Hash]
+		if !exists || parent == nil {
+			if !added[outpoint.Hash] {
+				added[outpoint.Hash] = true
+				missing.Hashes = append(missing.Hashes, outpoint.Hash)
+			}
+			continue
+		}
+
+		if !checked[outpoint.Hash] {
+			if hash := parent.TxHash(); !hash.Equal(&outpoint.Hash) {
+				return errors.Wrapf(ErrParentMismatch, "input %d: parent hash %s, want %s", i,
+					hash, outpoint.Hash)
+			}
+			checked[outpoint.Hash] = true
+		}
+
+		if int(outpoint.Index) >= len(parent.TxOut) {
+			return fmt.Errorf("Parent tx output index out of range : input %d: %d >= %d", i,
+				outpoint.Index, len(parent.TxOut))
+		}
+
+		byOutPoint[outpoint] = bitcoin.UTXO{
+			Hash:          outpoint.Hash,
+			Index:         outpoint.Index,
+			Value:         parent.TxOut[outpoint.Index].Value,
+			LockingScript: parent.TxOut[outpoint.Index].LockingScript,
+		}
+	}
+
+	if err := itx.promotePartial(byOutPoint, opts); err != nil {
+		return err
+	}
+
+	for i, txin := range itx.MsgTx.TxIn {
+		if !itx.Inputs[i].Unknown && txin.PreviousOutPoint.Index != 0xffffffff {
+			itx.Inputs[i].ParentTx = parents[txin.PreviousOutPoint.Hash]
+		}
+	}
+
+	if len(missing.Hashes) > 0 {
+		return missing
+	}
+
+	return nil
+}
+
+// promotePartial populates the inputs from the UTXOs, marking those that aren't found as Unknown,
+// and parses the outputs.
+func (itx *Transaction) promotePartial(byOutPoint map[wire.OutPoint]bitcoin.UTXO,
+	opts ParseOptions) error {
+
+	itx.Network = opts.NetworkOrDefault()
+	itx.IsTest = opts.IsTest()
+
+	inputs := make([]*Input, len(itx.MsgTx.TxIn))
+	for i, txin := range itx.MsgTx.TxIn {
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			// Empty coinbase input
+			inputs[i] = &Input{}
+			continue
+		}
+
+		utxo, exists := byOutPoint[txin.PreviousOutPoint]
+		if !exists {
+			inputs[i] = &Input{Unknown: true}
+			continue
+		}
+
+		inputs[i] = &Input{
+			Value:         utxo.Value,
+			LockingScript: utxo.LockingScript,
+		}
+
+		data, err := decodeScript(utxo.LockingScript, opts)
+		if err != nil && opts.Strict {
+			return errors.Wrapf(err, "input %d", i)
+		}
+		inputs[i].setScriptData(data)
+	}
+	itx.Inputs = inputs
+
+	if err := itx.ParseOutputsWithOptions(opts); err != nil {
+		return errors.Wrap(err, "parse outputs")
+	}
+
+	return nil
+}
+
+// IsPromoted returns true if inputs and outputs are populated and no inputs are unknown.
 func (itx *Transaction) IsPromoted(ctx context.Context) bool {
 	itx.lock.RLock()
 	defer itx.lock.RUnlock()
 
-	return len(itx.Inputs) > 0 && len(itx.Outputs) > 0
+	return len(itx.Inputs) > 0 && len(itx.Outputs) > 0 && len(itx.unknownInputs()) == 0
+}
+
+// UnknownInputs returns the indexes of the inputs that partial promotion couldn't resolve.
+func (itx *Transaction) UnknownInputs() []int {
+	itx.lock.RLock()
+	defer itx.lock.RUnlock()
+
+	return itx.unknownInputs()
+}
+
+func (itx *Transaction) unknownInputs() []int {
+	var result []int
+	for i, input := range itx.Inputs {
+		if input.Unknown {
+			result = append(result, i)
+		}
+	}
+
+	return result
 }
 
 // ParseInputsFromUTXOs sets the Inputs property of the Transaction
 func (itx *Transaction) ParseInputsFromUTXOs(ctx context.Context, utxos []bitcoin.UTXO,
 	isTest bool) error {
+	return itx.ParseInputsFromUTXOsWithOptions(ctx, utxos, DefaultParseOptions(isTest))
+}
+
+// ParseInputsFromUTXOsWithOptions sets the Inputs property of the Transaction
+func (itx *Transaction) ParseInputsFromUTXOsWithOptions(ctx context.Context, utxos []bitcoin.UTXO,
+	opts ParseOptions) error {
 
 	// Build inputs
 	inputs := make([]*Input, len(itx.MsgTx.TxIn))
@@ -238,6 +430,10 @@ func (itx *Transaction) ParseInputsFromUTXOs(ctx context.Context, utxos []bitcoi
 			continue
 		}
 
+		if offset >= len(utxos) {
+			return errors.New("Missing UTXO")
+		}
+
 		if !txin.PreviousOutPoint.Hash.Equal(&utxos[offset].Hash) ||
 			txin.PreviousOutPoint.Index != utxos[offset].Index {
 			return errors.New("Mismatched UTXO")
@@ -248,10 +444,11 @@ func (itx *Transaction) ParseInputsFromUTXOs(ctx context.Context, utxos []bitcoi
 			LockingScript: utxos[offset].LockingScript,
 		}
 
-		action, err := protocol.Deserialize(utxos[offset].LockingScript, isTest)
-		if err == nil {
-			inputs[i].Action = action
+		data, err := decodeScript(utxos[offset].LockingScript, opts)
+		if err != nil && opts.Strict {
+			return errors.Wrapf(err, "input %d", i)
 		}
+		inputs[i].setScriptData(data)
 
 		offset++
 	}
@@ -262,14 +459,20 @@ func (itx *Transaction) ParseInputsFromUTXOs(ctx context.Context, utxos []bitcoi
 
 // ParseOutputs sets the Outputs property of the Transaction
 func (itx *Transaction) ParseOutputs(isTest bool) error {
+	return itx.ParseOutputsWithOptions(DefaultParseOptions(isTest))
+}
+
+// ParseOutputsWithOptions sets the Outputs property of the Transaction
+func (itx *Transaction) ParseOutputsWithOptions(opts ParseOptions) error {
 	outputs := make([]*Output, len(itx.MsgTx.TxOut))
 	for i, txout := range itx.MsgTx.TxOut {
 		outputs[i] = &Output{}
 
-		action, err := protocol.Deserialize(txout.LockingScript, isTest)
-		if err == nil {
-			outputs[i].Action = action
+		data, err := decodeScript(txout.LockingScript, opts)
+		if err != nil && opts.Strict {
+			return errors.Wrapf(err, "output %d", i)
 		}
+		outputs[i].setScriptData(data)
 	}
 
 	itx.Outputs = outputs
@@ -278,6 +481,12 @@ func (itx *Transaction) ParseOutputs(isTest bool) error {
 
 // ParseInputs sets the Inputs property of the Transaction
 func (itx *Transaction) ParseInputs(ctx context.Context, node NodeInterface, isTest bool) error {
+	return itx.ParseInputsWithOptions(ctx, node, DefaultParseOptions(isTest))
+}
+
+// ParseInputsWithOptions sets the Inputs property of the Transaction
+func (itx *Transaction) ParseInputsWithOptions(ctx context.Context, node NodeInterface,
+	opts ParseOptions) error {
 
 	// Fetch input transactions from RPC
 	outpoints := make([]wire.OutPoint, 0, len(itx.MsgTx.TxIn))
@@ -292,7 +501,7 @@ func (itx *Transaction) ParseInputs(ctx context.Context, node NodeInterface, isT
 		return err
 	}
 
-	return itx.ParseInputsFromUTXOs(ctx, utxos, isTest)
+	return itx.ParseInputsFromUTXOsWithOptions(ctx, utxos, opts)
 }
 
 // Returns all the input hashes
@@ -370,7 +579,10 @@ func (itx *Transaction) fee() (uint64, error) {
 		return 0, ErrUnpromotedTx
 	}
 
-	for _, input := range itx.Inputs {
+	for i, input := range itx.Inputs {
+		if input.Unknown {
+			return 0, errors.Wrapf(ErrIncompleteTx, "input %d unknown", i)
+		}
 		result += input.Value
 	}
 
@@ -471,85 +683,171 @@ func appendIfDoesntExist(lockingScripts []bitcoin.Script,
 	return append(lockingScripts, lockingScript)
 }
 
+// Write writes the transaction in the current binary format. The format is a version byte, the
+// wire tx, the inputs, the reject code and text, the network and test flag used to parse, and a
+// checksum of the preceding bytes.
 func (itx *Transaction) Write(w io.Writer) error {
+	// The format can't represent unknown inputs.
+	if unknown := itx.unknownInputs(); len(unknown) > 0 {
+		return errors.Wrapf(ErrIncompleteTx, "input %d unknown", unknown[0])
+	}
+
+	hasher := sha256.New()
+	hw := io.MultiWriter(w, hasher)
+
 	// Version
-	if _, err := w.Write([]byte{3}); err != nil {
+	if _, err := hw.Write([]byte{CurrentVersion}); err != nil {
 		return errors.Wrap(err, "version")
 	}
 
-	if err := itx.MsgTx.Serialize(w); err != nil {
+	if err := itx.MsgTx.Serialize(hw); err != nil {
 		return errors.Wrap(err, "tx")
 	}
 
-	if err := binary.Write(w, binary.LittleEndian, uint32(len(itx.Inputs))); err != nil {
+	if err := binary.Write(hw, binary.LittleEndian, uint32(len(itx.Inputs))); err != nil {
 		return errors.Wrap(err, "inputs count")
 	}
 
 	for i, _ := range itx.Inputs {
-		if err := itx.Inputs[i].Write(w); err != nil {
+		if err := itx.Inputs[i].Write(hw); err != nil {
 			return errors.Wrapf(err, "input %d", i)
 		}
 	}
 
-	if _, err := w.Write([]byte{uint8(itx.RejectCode)}); err != nil {
+	if err := binary.Write(hw, binary.LittleEndian, itx.RejectCode); err != nil {
 		return errors.Wrap(err, "reject code")
 	}
 
+	if err := wire.WriteVarString(hw, 0, itx.RejectText); err != nil {
+		return errors.Wrap(err, "reject text")
+	}
+
+	if err := binary.Write(hw, binary.LittleEndian, uint32(itx.Network)); err != nil {
+		return errors.Wrap(err, "network")
+	}
+
+	if err := binary.Write(hw, binary.LittleEndian, itx.IsTest); err != nil {
+		return errors.Wrap(err, "is test")
+	}
+
+	checksum := sha256.Sum256(hasher.Sum(nil))
+	if _, err := w.Write(checksum[:checksumSize]); err != nil {
+		return errors.Wrap(err, "checksum")
+	}
+
 	return nil
 }
 
 func (itx *Transaction) Read(r io.Reader, isTest bool) error {
+	return itx.ReadWithOptions(r, DefaultParseOptions(isTest))
+}
+
+// ReadWithOptions reads a transaction written by Write and decodes its data as specified by the
+// options. Network and IsTest are set from the options unless they were written with the
+// transaction.
+func (itx *Transaction) ReadWithOptions(r io.Reader, opts ParseOptions) error {
+	limits := opts.ReadLimits.orDefault()
+
+	hasher := sha256.New()
+	r = io.TeeReader(newLimitedReader(r, limits.MaxSize), hasher)
+
 	// Version
 	var version [1]byte
 	if _, err := io.ReadFull(r, version[:]); err != nil {
 		return errors.Wrap(err, "version")
 	}
-	if version[0] != 0 && version[0] != 1 && version[0] != 2 && version[0] != 3 {
+	if version[0] > CurrentVersion {
 		return fmt.Errorf("Unknown version : %d", version[0])
 	}
 
-	msg := wire.MsgTx{}
-	if err := msg.Deserialize(r); err != nil {
+	msg, err := readMsgTx(r, limits)
+	if err != nil {
 		return errors.Wrap(err, "tx")
 	}
-	itx.MsgTx = &msg
+	itx.MsgTx = msg
 	itx.Hash = *msg.TxHash()
 
 	// Inputs
 	var count uint32
 	if version[0] >= 2 {
-		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		count, err = readInputCount(r, limits)
+		if err != nil {
 			return errors.Wrap(err, "inputs count")
 		}
 	} else {
 		count = uint32(len(msg.TxIn))
 	}
 
-	itx.Inputs = make([]*Input, count)
-	for i, _ := range itx.Inputs {
+	// Inputs are appended as they are read so the count doesn't determine the allocation.
+	itx.Inputs = nil
+	for i := uint32(0); i < count; i++ {
 		input := &Input{}
-		if err := input.Read(version[0], r); err != nil {
+		if err := input.ReadWithLimits(version[0], r, limits); err != nil {
 			return errors.Wrapf(err, "input %d", i)
 		}
-		itx.Inputs[i] = input
+
+		if version[0] == 0 && msg.TxIn[i].PreviousOutPoint.Index != 0xffffffff {
+			// Version 0 data was written without checking the parents, so a parent that doesn't
+			// contain the spent output leaves the input unknown rather than failing the read.
+			if err := input.resolveFromParent(msg.TxIn[i].PreviousOutPoint); err != nil {
+				input.Unknown = true
+			}
+		}
+
+		itx.Inputs = append(itx.Inputs, input)
 	}
 
-	var rejectCode [1]byte
-	if _, err := io.ReadFull(r, rejectCode[:]); err != nil {
-		return errors.Wrap(err, "reject code")
+	itx.Network = opts.NetworkOrDefault()
+	itx.IsTest = opts.IsTest()
+
+	if version[0] >= 4 {
+		if err := binary.Read(r, binary.LittleEndian, &itx.RejectCode); err != nil {
+			return errors.Wrap(err, "reject code")
+		}
+
+		rejectText, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return errors.Wrap(err, "reject text")
+		}
+		itx.RejectText = rejectText
+
+		var network uint32
+		if err := binary.Read(r, binary.LittleEndian, &network); err != nil {
+			return errors.Wrap(err, "network")
+		}
+		itx.Network = bitcoin.Network(network)
+
+		if err := binary.Read(r, binary.LittleEndian, &itx.IsTest); err != nil {
+			return errors.Wrap(err, "is test")
+		}
+
+		calculated := sha256.Sum256(hasher.Sum(nil))
+		var checksum [checksumSize]byte
+		if _, err := io.ReadFull(r, checksum[:]); err != nil {
+			return errors.Wrap(err, "checksum")
+		}
+		if !bytes.Equal(checksum[:], calculated[:checksumSize]) {
+			return ErrInvalidChecksum
+		}
+	} else {
+		var rejectCode [1]byte
+		if _, err := io.ReadFull(r, rejectCode[:]); err != nil {
+			return errors.Wrap(err, "reject code")
+		}
+		itx.RejectCode = uint32(rejectCode[0])
 	}
-	itx.RejectCode = uint32(rejectCode[0])
 
 	// Parse data
 	for i, input := range itx.Inputs {
-		action, err := protocol.Deserialize(input.LockingScript, isTest)
-		if err == nil {
-			itx.Inputs[i].Action = action
+		data, err := decodeScript(input.LockingScript, opts)
+		if err != nil && opts.Strict {
+			return errors.Wrapf(err, "input %d", i)
 		}
+		itx.Inputs[i].setScriptData(data)
 	}
 
-	if err := itx.ParseOutputs(isTest); err != nil {
-		return errors.Wrap(err, "parse inputs")
+	if err := itx.ParseOutputsWithOptions(opts); err != nil {
+		return errors.Wrap(err, "parse outputs")
 	}
 
 	return nil
@@ -579,6 +877,10 @@ func (itx *Transaction) InputOutput(index int) (*wire.TxOut, error) {
 	}
 
 	input := itx.Inputs[index]
+	if input.Unknown {
+		return nil, errors.Wrapf(ErrIncompleteTx, "input %d unknown", index)
+	}
+
 	return &wire.TxOut{
 		Value:         input.Value,
 		LockingScript: input.LockingScript,
@@ -0,0 +1,200 @@
+package inspector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// decoderDetectSize is the number of bytes checked to detect a hex stream.
+	decoderDetectSize = 64
+)
+
+// RecordError is returned by TransactionDecoder for a record that can't be decoded.
+type RecordError struct {
+	// Line is the line number, starting at 1, of the record in a hex stream. It is zero in binary
+	// streams.
+	Line int
+
+	// Offset is the byte offset of the start of the record in the stream.
+	Offset uint64
+
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	if e.Line != 0 {
+		return fmt.Sprintf("line %d (offset %d): %s", e.Line, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Err)
+}
+
+// Cause returns the error decoding the record so errors.Cause finds it.
+func (e *RecordError) Cause() error {
+	return e.Err
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// TransactionDecoder reads transactions one at a time from a stream of newline separated hex txs
+// or concatenated binary txs. The format is detected from the start of the stream.
+//
+// Decoding continues after a record that fails with a *RecordError. In a hex stream each line is a
+// record, so decoding always continues with the next line. In a binary stream the end of a record
+// that can't be deserialized isn't known, so the stream ends after that error.
+type TransactionDecoder struct {
+	r    *bufio.Reader
+	opts ParseOptions
+
+	detected bool
+	isHex    bool
+	line     int
+	offset   uint64
+	done     bool
+}
+
+// NewTransactionDecoder creates a TransactionDecoder that reads from r.
+func NewTransactionDecoder(r io.Reader, isTest bool) *TransactionDecoder {
+	return NewTransactionDecoderWithOptions(r, DefaultParseOptions(isTest))
+}
+
+// NewTransactionDecoderWithOptions creates a TransactionDecoder that reads from r.
+func NewTransactionDecoderWithOptions(r io.Reader, opts ParseOptions) *TransactionDecoder {
+	return &TransactionDecoder{
+		r:    bufio.NewReader(r),
+		opts: opts,
+	}
+}
+
+// IsHex returns true if the stream was detected as hex. It is only valid after the first call to
+// Next.
+func (d *TransactionDecoder) IsHex() bool {
+	return d.isHex
+}
+
+// Next returns the next transaction. It returns io.EOF at the end of the stream and a *RecordError
+// for a record that can't be decoded.
+func (d *TransactionDecoder) Next(ctx context.Context) (*Transaction, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+
+	if !d.detected {
+		if err := d.detect(); err != nil {
+			d.done = true
+			return nil, err
+		}
+	}
+
+	if d.isHex {
+		return d.nextHex(ctx)
+	}
+
+	return d.nextBinary(ctx)
+}
+
+// detect sets whether the stream is hex from its first bytes. Binary txs start with a version that
+// isn't made of hex characters.
+func (d *TransactionDecoder) detect() error {
+	b, err := d.r.Peek(decoderDetectSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return errors.Wrap(err, "detect")
+	}
+	if len(b) == 0 {
+		return io.EOF
+	}
+
+	d.detected = true
+	d.isHex = true
+	for _, c := range b {
+		if !isHexCharacter(c) && !isWhiteSpace(c) {
+			d.isHex = false
+			break
+		}
+	}
+
+	return nil
+}
+
+func (d *TransactionDecoder) nextHex(ctx context.Context) (*Transaction, error) {
+	for {
+		offset := d.offset
+		line, err := d.r.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			d.done = true
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, &RecordError{Line: d.line + 1, Offset: offset, Err: err}
+		}
+
+		d.line++
+		d.offset += uint64(len(line))
+
+		data := bytes.TrimSpace(line)
+		if len(data) == 0 {
+			continue // blank line
+		}
+
+		itx, parseErr := NewTransactionWithOptions(ctx, string(data), d.opts)
+		if parseErr != nil {
+			return nil, &RecordError{Line: d.line, Offset: offset, Err: parseErr}
+		}
+
+		return itx, nil
+	}
+}
+
+func (d *TransactionDecoder) nextBinary(ctx context.Context) (*Transaction, error) {
+	if _, err := d.r.Peek(1); err == io.EOF {
+		d.done = true
+		return nil, io.EOF
+	}
+
+	offset := d.offset
+	limits := d.opts.ReadLimits.orDefault()
+	counter := &countingReader{r: newLimitedReader(d.r, limits.MaxSize)}
+	tx, err := readMsgTx(counter, limits)
+	d.offset += counter.count
+	if err != nil {
+		// The start of the next record is unknown.
+		d.done = true
+		return nil, &RecordError{
+			Offset: offset,
+			Err:    decodeError(err, "deserializing wire message"),
+		}
+	}
+
+	itx, err := NewTransactionFromWireWithOptions(ctx, tx, d.opts)
+	if err != nil {
+		return nil, &RecordError{Offset: offset, Err: err}
+	}
+
+	return itx, nil
+}
+
+type countingReader struct {
+	r     io.Reader
+	count uint64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.count += uint64(n)
+	return n, err
+}
+
+func isHexCharacter(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isWhiteSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
@@ -0,0 +1,105 @@
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_TransactionDecoder_Hex(t *testing.T) {
+	ctx := context.Background()
+
+	txs := fixtureHex(t)
+	if len(txs) < 2 {
+		t.Fatalf("Not enough fixtures : %d", len(txs))
+	}
+
+	lines := []string{txs[0], "", "zz", txs[1] + "\r"}
+	decoder := NewTransactionDecoder(strings.NewReader(strings.Join(lines, "\n")), false)
+
+	itx, err := decoder.Next(ctx)
+	if err != nil {
+		t.Fatalf("Failed to decode first tx : %s", err)
+	}
+	if !decoder.IsHex() {
+		t.Fatalf("Hex not detected")
+	}
+	if itx.MsgTx == nil {
+		t.Fatalf("Missing tx")
+	}
+
+	_, err = decoder.Next(ctx)
+	recordErr, ok := err.(*RecordError)
+	if !ok {
+		t.Fatalf("Wrong error for bad record : got %v, want *RecordError", err)
+	}
+	if recordErr.Line != 3 || recordErr.Offset != uint64(len(txs[0])+2) {
+		t.Fatalf("Wrong error position : got line %d offset %d, want line 3 offset %d",
+			recordErr.Line, recordErr.Offset, len(txs[0])+2)
+	}
+	if errors.Cause(err) != ErrDecodeFail {
+		t.Fatalf("Wrong error cause : got %v, want %s", errors.Cause(err), ErrDecodeFail)
+	}
+
+	// Decoding continues after the bad record.
+	itx, err = decoder.Next(ctx)
+	if err != nil {
+		t.Fatalf("Failed to decode second tx : %s", err)
+	}
+
+	want, _ := NewTransaction(ctx, txs[1], false)
+	if !itx.Hash.Equal(&want.Hash) {
+		t.Fatalf("Wrong second tx : got %s, want %s", itx.Hash, want.Hash)
+	}
+
+	if _, err := decoder.Next(ctx); err != io.EOF {
+		t.Fatalf("Wrong error at end : got %v, want %s", err, io.EOF)
+	}
+}
+
+func Test_TransactionDecoder_Binary(t *testing.T) {
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	var lengths []int
+	for _, tx := range fixtureHex(t)[:2] {
+		b, err := hex.DecodeString(tx)
+		if err != nil {
+			t.Fatalf("Failed to decode hex : %s", err)
+		}
+		buf.Write(b)
+		lengths = append(lengths, len(b))
+	}
+
+	// Truncated record
+	buf.Write(buf.Bytes()[:10])
+
+	decoder := NewTransactionDecoder(buf, false)
+	for i := range lengths {
+		if _, err := decoder.Next(ctx); err != nil {
+			t.Fatalf("Failed to decode tx %d : %s", i, err)
+		}
+	}
+	if decoder.IsHex() {
+		t.Fatalf("Binary detected as hex")
+	}
+
+	_, err := decoder.Next(ctx)
+	recordErr, ok := err.(*RecordError)
+	if !ok {
+		t.Fatalf("Wrong error for truncated record : got %v, want *RecordError", err)
+	}
+	if recordErr.Offset != uint64(lengths[0]+lengths[1]) {
+		t.Fatalf("Wrong error offset : got %d, want %d", recordErr.Offset,
+			lengths[0]+lengths[1])
+	}
+
+	if _, err := decoder.Next(ctx); err != io.EOF {
+		t.Fatalf("Wrong error at end : got %v, want %s", err, io.EOF)
+	}
+}
@@ -2,17 +2,353 @@ package inspector
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"testing"
 
 	"github.com/tokenized/pkg/bitcoin"
+	"github.com/tokenized/pkg/wire"
+	"github.com/tokenized/specification/dist/golang/actions"
 	"github.com/tokenized/specification/dist/golang/protocol"
 
 	"github.com/pkg/errors"
 )
 
+func Test_Transaction_Serialize_v4(t *testing.T) {
+	itx := newTestPromotedTx(t)
+	itx.RejectCode = actions.RejectionsInsufficientValue + 1000
+	itx.RejectText = "Not enough"
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write(buf); err != nil {
+		t.Fatalf("Failed to write tx : %s", err)
+	}
+
+	if buf.Bytes()[0] != CurrentVersion {
+		t.Fatalf("Wrong version : got %d, want %d", buf.Bytes()[0], CurrentVersion)
+	}
+
+	// Read with different options to ensure the original parse metadata is retained.
+	readTx := &Transaction{}
+	if err := readTx.ReadWithOptions(bytes.NewReader(buf.Bytes()), ParseOptions{
+		ProtocolIDs: []string{protocol.ProtocolID, protocol.TestProtocolID},
+	}); err != nil {
+		t.Fatalf("Failed to read tx : %s", err)
+	}
+
+	if err := itx.Equal(readTx); err != nil {
+		t.Fatalf("Read tx not equal : %s", err)
+	}
+
+	if readTx.RejectText != itx.RejectText {
+		t.Fatalf("Wrong reject text : got %s, want %s", readTx.RejectText, itx.RejectText)
+	}
+
+	if readTx.Network != bitcoin.TestNet || !readTx.IsTest {
+		t.Fatalf("Wrong parse metadata : got %s %t, want %s %t", readTx.Network, readTx.IsTest,
+			bitcoin.TestNet, true)
+	}
+
+	// Corrupt the reject text
+	b := buf.Bytes()
+	b[len(b)-10] ^= 0xff
+	err := (&Transaction{}).Read(bytes.NewReader(b), true)
+	if errors.Cause(err) != ErrInvalidChecksum {
+		t.Fatalf("Corrupt data should fail checksum : %v", err)
+	}
+}
+
+func Test_Transaction_Serialize_v3_to_v4(t *testing.T) {
+	itx := newTestPromotedTx(t)
+	itx.RejectCode = actions.RejectionsMsgMalformed
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write_v3(buf); err != nil {
+		t.Fatalf("Failed to write tx : %s", err)
+	}
+
+	readTx := &Transaction{}
+	if err := readTx.Read(buf, true); err != nil {
+		t.Fatalf("Failed to read tx : %s", err)
+	}
+
+	if err := itx.Equal(readTx); err != nil {
+		t.Fatalf("Read tx not equal : %s", err)
+	}
+
+	if readTx.Network != bitcoin.MainNet || !readTx.IsTest {
+		t.Fatalf("Wrong parse metadata : got %s %t, want %s %t", readTx.Network, readTx.IsTest,
+			bitcoin.MainNet, true)
+	}
+}
+
+func Test_Transaction_Serialize_v0(t *testing.T) {
+	ctx := context.Background()
+
+	parentTx := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN})
+	parentTx.AddTxOut(wire.NewTxOut(5000, parentTx.TxOut[0].LockingScript))
+
+	script, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+	tx := newTestTx(t, script)
+	tx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(parentTx.TxHash(), 2)
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write_v0(buf, []*wire.MsgTx{parentTx}); err != nil {
+		t.Fatalf("Failed to write tx : %s", err)
+	}
+
+	readTx := &Transaction{}
+	if err := readTx.Read(buf, true); err != nil {
+		t.Fatalf("Failed to read tx : %s", err)
+	}
+
+	if readTx.Inputs[0].Value != 5000 {
+		t.Fatalf("Wrong input value : got %d, want %d", readTx.Inputs[0].Value, 5000)
+	}
+
+	if !readTx.Inputs[0].LockingScript.Equal(parentTx.TxOut[2].LockingScript) {
+		t.Fatalf("Wrong input locking script : got %s, want %s", readTx.Inputs[0].LockingScript,
+			parentTx.TxOut[2].LockingScript)
+	}
+
+	readParentTx := readTx.Inputs[0].ParentTx
+	if readParentTx == nil || !readParentTx.TxHash().Equal(parentTx.TxHash()) {
+		t.Fatalf("Parent tx not retained")
+	}
+
+	fee, err := readTx.Fee()
+	if err != nil {
+		t.Fatalf("Failed to calculate fee : %s", err)
+	}
+	if fee != 4000 {
+		t.Fatalf("Wrong fee : got %d, want %d", fee, 4000)
+	}
+}
+
+// A v0 parent that doesn't match the input leaves the input unknown, so the tx reads but can't be
+// written.
+func Test_Transaction_Read_v0_WrongParent(t *testing.T) {
+	ctx := context.Background()
+
+	readTx := &Transaction{}
+	if err := readTx.Read(bytes.NewReader(wrongParentRecord(t)), true); err != nil {
+		t.Fatalf("Failed to read tx with wrong parent : %s", err)
+	}
+
+	if unknown := readTx.UnknownInputs(); len(unknown) != len(readTx.Inputs) {
+		t.Fatalf("Wrong unknown inputs : got %v, want all %d", unknown, len(readTx.Inputs))
+	}
+
+	if readTx.IsPromoted(ctx) {
+		t.Fatalf("Tx with wrong parent should not be promoted")
+	}
+
+	if err := readTx.Write(&bytes.Buffer{}); errors.Cause(err) != ErrIncompleteTx {
+		t.Fatalf("Wrong write error : got %v, want %s", err, ErrIncompleteTx)
+	}
+}
+
+func Test_Transaction_PromotePartial(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+
+	var unknownHash bitcoin.Hash32
+	unknownHash[0] = 2
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 1), nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&unknownHash, 0), nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxOut(wire.NewTxOut(900, parent.TxOut[0].LockingScript))
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	// Not in input order and missing the second input's output.
+	utxos := parentUTXOs(parent)
+	if err := itx.PromoteFromUTXOs(ctx, utxos, true); err == nil {
+		t.Fatalf("Promoted with missing UTXO")
+	}
+
+	if err := itx.PromotePartialFromUTXOs(ctx, utxos, true); err != nil {
+		t.Fatalf("Failed to promote partial : %s", err)
+	}
+
+	if itx.IsPromoted(ctx) {
+		t.Fatalf("Partially promoted tx reported as promoted")
+	}
+
+	if unknown := itx.UnknownInputs(); len(unknown) != 1 || unknown[0] != 1 {
+		t.Fatalf("Wrong unknown inputs : got %v, want [1]", unknown)
+	}
+
+	if itx.Inputs[0].Value != 0 || itx.Inputs[2].Value != 1000 {
+		t.Fatalf("Wrong input values : got %d %d, want 0 1000", itx.Inputs[0].Value,
+			itx.Inputs[2].Value)
+	}
+
+	if _, err := itx.Fee(); errors.Cause(err) != ErrIncompleteTx {
+		t.Fatalf("Wrong fee error : got %v, want %s", err, ErrIncompleteTx)
+	}
+
+	if _, err := itx.FeeRate(); errors.Cause(err) != ErrIncompleteTx {
+		t.Fatalf("Wrong fee rate error : got %v, want %s", err, ErrIncompleteTx)
+	}
+
+	if err := itx.Write(&bytes.Buffer{}); errors.Cause(err) != ErrIncompleteTx {
+		t.Fatalf("Wrong write error : got %v, want %s", err, ErrIncompleteTx)
+	}
+
+	// All outputs available
+	utxos = append(utxos, bitcoin.UTXO{
+		Hash:          unknownHash,
+		Index:         0,
+		Value:         500,
+		LockingScript: parent.TxOut[0].LockingScript,
+	})
+	if err := itx.PromotePartialFromUTXOs(ctx, utxos, true); err != nil {
+		t.Fatalf("Failed to promote partial : %s", err)
+	}
+
+	if !itx.IsPromoted(ctx) {
+		t.Fatalf("Not promoted")
+	}
+
+	fee, err := itx.Fee()
+	if err != nil {
+		t.Fatalf("Failed to get fee : %s", err)
+	}
+	if fee != 600 {
+		t.Fatalf("Wrong fee : got %d, want %d", fee, 600)
+	}
+}
+
+func parentUTXOs(tx *wire.MsgTx) []bitcoin.UTXO {
+	var result []bitcoin.UTXO
+	for i, output := range tx.TxOut {
+		result = append(result, bitcoin.UTXO{
+			Hash:          *tx.TxHash(),
+			Index:         uint32(i),
+			Value:         output.Value,
+			LockingScript: output.LockingScript,
+		})
+	}
+
+	return result
+}
+
+func Test_Transaction_PromoteFromParents(t *testing.T) {
+	ctx := context.Background()
+
+	parent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 1})
+	otherParent := newTestTx(t, bitcoin.Script{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 2})
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(otherParent.TxHash(), 0), nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(otherParent.TxHash(), 1), nil))
+	tx.AddTxOut(wire.NewTxOut(1500, parent.TxOut[0].LockingScript))
+
+	itx, err := NewTransactionFromWire(ctx, tx, true)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	parents := map[bitcoin.Hash32]*wire.MsgTx{*parent.TxHash(): parent}
+	err = itx.PromoteFromParents(ctx, parents, DefaultParseOptions(true))
+	missingErr, ok := err.(*MissingParentsError)
+	if !ok {
+		t.Fatalf("Wrong error for missing parent : got %v, want *MissingParentsError", err)
+	}
+	if len(missingErr.Hashes) != 1 || !missingErr.Hashes[0].Equal(otherParent.TxHash()) {
+		t.Fatalf("Wrong missing parents : got %v, want [%s]", missingErr.Hashes,
+			otherParent.TxHash())
+	}
+	if errors.Cause(err) != ErrMissingParentTx {
+		t.Fatalf("Wrong error cause : got %v, want %s", errors.Cause(err), ErrMissingParentTx)
+	}
+
+	if itx.IsPromoted(ctx) {
+		t.Fatalf("Tx with missing parents reported as promoted")
+	}
+
+	if itx.Inputs[0].ParentTx != parent || itx.Inputs[0].Value != 1000 {
+		t.Fatalf("Wrong first input : got %d, want %d with parent", itx.Inputs[0].Value, 1000)
+	}
+
+	if unknown := itx.UnknownInputs(); len(unknown) != 2 {
+		t.Fatalf("Wrong unknown inputs : got %v, want [1 2]", unknown)
+	}
+
+	parents[*otherParent.TxHash()] = otherParent
+	if err := itx.PromoteFromParents(ctx, parents, DefaultParseOptions(true)); err != nil {
+		t.Fatalf("Failed to promote : %s", err)
+	}
+
+	if !itx.IsPromoted(ctx) {
+		t.Fatalf("Not promoted")
+	}
+
+	for i, input := range itx.Inputs {
+		if input.ParentTx == nil || input.Unknown {
+			t.Fatalf("Input %d not resolved from parent", i)
+		}
+	}
+
+	fee, err := itx.Fee()
+	if err != nil {
+		t.Fatalf("Failed to get fee : %s", err)
+	}
+	if fee != 500 {
+		t.Fatalf("Wrong fee : got %d, want %d", fee, 500)
+	}
+
+	// A parent under the wrong hash is rejected rather than used for the input.
+	parents[*otherParent.TxHash()] = parent
+	if err := itx.PromoteFromParents(ctx, parents,
+		DefaultParseOptions(true)); errors.Cause(err) != ErrParentMismatch {
+		t.Fatalf("Wrong error for wrong parent : got %v, want %s", err, ErrParentMismatch)
+	}
+}
+
+// newTestPromotedTx returns a promoted tx, parsed for test net and the test protocol, containing an
+// action.
+func newTestPromotedTx(t *testing.T) *Transaction {
+	ctx := context.Background()
+
+	script, err := protocol.Serialize(&actions.ContractOffer{ContractName: "Test"}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+	tx := newTestTx(t, script)
+
+	lockingScript := tx.TxOut[0].LockingScript
+	outputs := []*wire.TxOut{wire.NewTxOut(2000, lockingScript)}
+
+	opts := DefaultParseOptions(true)
+	opts.Network = bitcoin.TestNet
+	itx, err := NewTransactionFromOutputsWithOptions(ctx, *tx.TxHash(), tx, outputs, opts)
+	if err != nil {
+		t.Fatalf("Failed to create transaction : %s", err)
+	}
+
+	return itx
+}
+
 // func Test_Transaction_Serialize(t *testing.T) {
 // 	ctx := context.Background()
 
@@ -101,7 +437,7 @@ import (
 
 // 	t.Logf("Read Tx %s", readTx.String(bitcoin.MainNet))
 
-// 	if err := tx.Equal(*readTx); err != nil {
+// 	if err := tx.Equal(readTx); err != nil {
 // 		t.Fatalf("Read tx not equal : %s", err)
 // 	}
 // }
@@ -194,12 +530,12 @@ import (
 
 // 	t.Logf("Read Tx %s", readTx.String(bitcoin.MainNet))
 
-// 	if err := tx.Equal(*readTx); err != nil {
+// 	if err := tx.Equal(readTx); err != nil {
 // 		t.Fatalf("Read tx not equal : %s", err)
 // 	}
 // }
 
-func (itx Transaction) Equal(itx2 Transaction) error {
+func (itx *Transaction) Equal(itx2 *Transaction) error {
 	if !itx.Hash.Equal(&itx2.Hash) {
 		return fmt.Errorf("Wrong hash : got %s, want %s", itx.Hash, itx2.Hash)
 	}
@@ -293,6 +629,73 @@ func (itx Transaction) Equal(itx2 Transaction) error {
 	return nil
 }
 
+// wrongParentRecord returns a v0 record of a fixture tx with the tx itself in place of each parent.
+func wrongParentRecord(tb testing.TB) []byte {
+	itx := fixtureTransactions(tb)[0]
+
+	parents := make([]*wire.MsgTx, len(itx.MsgTx.TxIn))
+	for i := range parents {
+		parents[i] = itx.MsgTx
+	}
+
+	buf := &bytes.Buffer{}
+	if err := itx.Write_v0(buf, parents); err != nil {
+		tb.Fatalf("Failed to write tx : %s", err)
+	}
+
+	return buf.Bytes()
+}
+
+func (itx *Transaction) Write_v0(w io.Writer, parentTxs []*wire.MsgTx) error {
+	// Version
+	if _, err := w.Write([]byte{0}); err != nil {
+		return errors.Wrap(err, "version")
+	}
+
+	if err := itx.MsgTx.Serialize(w); err != nil {
+		return errors.Wrap(err, "tx")
+	}
+
+	for i, parentTx := range parentTxs {
+		if err := parentTx.Serialize(w); err != nil {
+			return errors.Wrapf(err, "parent tx %d", i)
+		}
+	}
+
+	if _, err := w.Write([]byte{uint8(itx.RejectCode)}); err != nil {
+		return errors.Wrap(err, "reject code")
+	}
+
+	return nil
+}
+
+func (itx *Transaction) Write_v3(w io.Writer) error {
+	// Version
+	if _, err := w.Write([]byte{3}); err != nil {
+		return errors.Wrap(err, "version")
+	}
+
+	if err := itx.MsgTx.Serialize(w); err != nil {
+		return errors.Wrap(err, "tx")
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(itx.Inputs))); err != nil {
+		return errors.Wrap(err, "inputs count")
+	}
+
+	for i, _ := range itx.Inputs {
+		if err := itx.Inputs[i].Write(w); err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+	}
+
+	if _, err := w.Write([]byte{uint8(itx.RejectCode)}); err != nil {
+		return errors.Wrap(err, "reject code")
+	}
+
+	return nil
+}
+
 func (itx *Transaction) Write_v2(w io.Writer) error {
 	// Version
 	if _, err := w.Write([]byte{2}); err != nil {